@@ -0,0 +1,133 @@
+package analytics
+
+import (
+	"hpcs/models"
+	"math"
+)
+
+// DefaultMinGroupSize は集団の集計を公開する最小の人数の既定値です。
+// これより少ない人数の集計は個人の得点を推測できるため返しません
+const DefaultMinGroupSize = 5
+
+// homogeneityRatio は均質とみなす標準偏差の、尺度の幅に対する割合です（5件法では 0.5）
+const homogeneityRatio = 0.125
+
+// binWidth はヒストグラムの階級の幅です
+const binWidth = 0.5
+
+// HistogramBin はヒストグラムの1つの階級です。最後の階級のみ上限を含みます
+type HistogramBin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// DimensionProfile は1つの次元の集団の得点分布です。人数が最小人数に満たない場合は統計量を省略します
+type DimensionProfile struct {
+	Dimension string `json:"dimension"`
+	// N はこの次元のスコアがある人数です
+	N          int      `json:"n"`
+	Suppressed bool     `json:"suppressed"`
+	Mean       *float64 `json:"mean,omitempty"`
+	SD         *float64 `json:"sd,omitempty"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	// Histogram は尺度の範囲を binWidth ごとに区切った度数分布です
+	Histogram []HistogramBin `json:"histogram,omitempty"`
+	// Homogeneous は標準偏差が尺度の幅に比べて小さく、集団の得点がそろっているかどうかです
+	Homogeneous bool `json:"homogeneous"`
+}
+
+// GroupProfile は集団の次元ごとの得点分布です
+type GroupProfile struct {
+	InstrumentID string `json:"instrumentId"`
+	Respondents  int    `json:"respondents"`
+	MinGroupSize int    `json:"minGroupSize"`
+	// Suppressed は回答者が最小人数に満たず、集計を公開しないかどうかです
+	Suppressed bool               `json:"suppressed"`
+	Dimensions []DimensionProfile `json:"dimensions"`
+	// HomogeneousDimensions は得点がそろっている次元のIDです
+	HomogeneousDimensions []string `json:"homogeneousDimensions"`
+}
+
+// Profile は回答者ごとの診断結果から次元ごとの平均・標準偏差・範囲・ヒストグラムを求めます。
+// スコアのある人数が minGroupSize に満たない次元は統計量を返しません
+func Profile(inst *models.Instrument, results []models.Result, minGroupSize int) GroupProfile {
+	if minGroupSize < 1 {
+		minGroupSize = 1
+	}
+	profile := GroupProfile{
+		InstrumentID:          inst.ID,
+		Respondents:           len(results),
+		MinGroupSize:          minGroupSize,
+		Suppressed:            len(results) < minGroupSize,
+		Dimensions:            []DimensionProfile{},
+		HomogeneousDimensions: []string{},
+	}
+
+	for _, dimension := range inst.DimensionIDs() {
+		var scores []float64
+		for _, r := range results {
+			if score, ok := r.Get(dimension); ok {
+				scores = append(scores, score)
+			}
+		}
+
+		d := dimensionProfile(inst.Scale, dimension, scores, minGroupSize)
+		if d.Homogeneous {
+			profile.HomogeneousDimensions = append(profile.HomogeneousDimensions, dimension)
+		}
+		profile.Dimensions = append(profile.Dimensions, d)
+	}
+	return profile
+}
+
+// dimensionProfile は1つの次元の得点分布を求めます
+func dimensionProfile(scale models.Scale, dimension string, scores []float64, minGroupSize int) DimensionProfile {
+	d := DimensionProfile{Dimension: dimension, N: len(scores)}
+	if d.N < minGroupSize {
+		d.Suppressed = true
+		return d
+	}
+
+	lo, hi := scores[0], scores[0]
+	for _, s := range scores {
+		lo, hi = math.Min(lo, s), math.Max(hi, s)
+	}
+	d.Mean = Round3(mean(scores))
+	d.Min = Round3(lo)
+	d.Max = Round3(hi)
+	d.Histogram = histogram(scale, scores)
+
+	// 1人のみの場合は標準偏差を計算できない
+	if d.N > 1 {
+		sd := math.Sqrt(variance(scores))
+		d.SD = Round3(sd)
+		d.Homogeneous = sd < homogeneityRatio*float64(scale.Max-scale.Min)
+	}
+	return d
+}
+
+// histogram は尺度の範囲を binWidth ごとに区切って度数を数えます。範囲外のスコアは両端の階級に含めます
+func histogram(scale models.Scale, scores []float64) []HistogramBin {
+	n := int(math.Ceil(float64(scale.Max-scale.Min) / binWidth))
+	if n < 1 {
+		n = 1
+	}
+	bins := make([]HistogramBin, n)
+	for i := range bins {
+		bins[i].From = float64(scale.Min) + float64(i)*binWidth
+		bins[i].To = math.Min(bins[i].From+binWidth, float64(scale.Max))
+	}
+	for _, s := range scores {
+		i := int(math.Floor((s - float64(scale.Min)) / binWidth))
+		if i < 0 {
+			i = 0
+		}
+		if i >= n {
+			i = n - 1
+		}
+		bins[i].Count++
+	}
+	return bins
+}
@@ -0,0 +1,83 @@
+package analytics
+
+import (
+	"hpcs/models"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	inst := testInstrument(t)
+	scores := [][2]float64{{3, 1}, {3.25, 5}, {3, 2}, {2.75, 4}, {3.5, 5}}
+	results := make([]models.Result, len(scores))
+	for i, s := range scores {
+		results[i].Set("a", models.Float64(s[0]))
+		results[i].Set("b", models.Float64(s[1]))
+	}
+	// 次元 b のスコアがない回答者
+	results = append(results, models.Result{Extra: map[string]*float64{"a": models.Float64(3)}})
+
+	profile := Profile(inst, results, 5)
+	if profile.Suppressed || profile.Respondents != 6 || len(profile.Dimensions) != 2 {
+		t.Fatalf("Unexpected profile: %+v", profile)
+	}
+
+	a, b := profile.Dimensions[0], profile.Dimensions[1]
+	if a.N != 6 || !almostEqual(a.Mean, 3.083, 0.001) || !almostEqual(a.Min, 2.75, 0) || !almostEqual(a.Max, 3.5, 0) {
+		t.Errorf("Unexpected profile of a: %+v", a)
+	}
+	if !a.Homogeneous || b.Homogeneous {
+		t.Errorf("Expected only a to be homogeneous, got a=%v b=%v", a.Homogeneous, b.Homogeneous)
+	}
+	if len(profile.HomogeneousDimensions) != 1 || profile.HomogeneousDimensions[0] != "a" {
+		t.Errorf("Unexpected homogeneous dimensions: %v", profile.HomogeneousDimensions)
+	}
+	if b.N != 5 || !almostEqual(b.SD, 1.817, 0.001) {
+		t.Errorf("Unexpected profile of b: %+v", b)
+	}
+
+	// 1〜5件法は 0.5 刻みの8階級。上限の5は最後の階級に含める
+	if len(b.Histogram) != 8 || b.Histogram[0].Count != 1 || b.Histogram[2].Count != 1 || b.Histogram[6].Count != 1 || b.Histogram[7].Count != 2 {
+		t.Errorf("Unexpected histogram of b: %+v", b.Histogram)
+	}
+	if last := b.Histogram[7]; last.From != 4.5 || last.To != 5 {
+		t.Errorf("Unexpected last bin: %+v", last)
+	}
+}
+
+func TestProfileMinGroupSize(t *testing.T) {
+	inst := testInstrument(t)
+	results := []models.Result{
+		{Extra: map[string]*float64{"a": models.Float64(3), "b": models.Float64(2)}},
+		{Extra: map[string]*float64{"a": models.Float64(4)}},
+		{Extra: map[string]*float64{"a": models.Float64(5)}},
+	}
+
+	tests := []struct {
+		name       string
+		min        int
+		suppressed bool
+		suppressA  bool
+		suppressB  bool
+	}{
+		{name: "最小人数に満たない", min: 5, suppressed: true, suppressA: true, suppressB: true},
+		{name: "スコアのある人数が最小人数に満たない次元のみ省略", min: 3, suppressB: true},
+		{name: "最小人数が1", min: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := Profile(inst, results, tt.min)
+			a, b := profile.Dimensions[0], profile.Dimensions[1]
+			if profile.Suppressed != tt.suppressed || a.Suppressed != tt.suppressA || b.Suppressed != tt.suppressB {
+				t.Errorf("Expected suppressed %v/%v/%v, got %v/%v/%v", tt.suppressed, tt.suppressA, tt.suppressB, profile.Suppressed, a.Suppressed, b.Suppressed)
+			}
+			if b.Suppressed && (b.Mean != nil || b.Histogram != nil) {
+				t.Errorf("Expected suppressed dimension to have no statistics: %+v", b)
+			}
+			// 1人のみの次元は標準偏差を計算しない
+			if !b.Suppressed && (b.SD != nil || b.Homogeneous) {
+				t.Errorf("Expected no SD for a single respondent: %+v", b)
+			}
+		})
+	}
+}
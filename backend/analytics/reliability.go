@@ -0,0 +1,115 @@
+// Package analytics は回答データから尺度の信頼性などの統計量を求めます
+package analytics
+
+import (
+	"hpcs/models"
+	"math"
+)
+
+// ItemReliability は1項目の統計量です。計算できない値は nil になります
+type ItemReliability struct {
+	QuestionID int      `json:"questionId"`
+	Mean       *float64 `json:"mean"`
+	SD         *float64 `json:"sd"`
+	// ItemTotalCorrelation は修正済み項目-合計相関（その項目を除いた合計点との相関）です
+	ItemTotalCorrelation *float64 `json:"itemTotalCorrelation"`
+	AlphaIfDeleted       *float64 `json:"alphaIfDeleted"`
+}
+
+// DimensionReliability は1つの次元の内的整合性です
+type DimensionReliability struct {
+	Dimension string `json:"dimension"`
+	// N は次元のすべての項目に回答した（リストワイズ除去後の）回答者数です
+	N     int               `json:"n"`
+	Alpha *float64          `json:"alpha"`
+	Omega *float64          `json:"omega"`
+	Items []ItemReliability `json:"items"`
+}
+
+// ReliabilityReport は検査全体の信頼性の集計です
+type ReliabilityReport struct {
+	InstrumentID string                 `json:"instrumentId"`
+	Respondents  int                    `json:"respondents"`
+	Dimensions   []DimensionReliability `json:"dimensions"`
+}
+
+// minRespondents は統計量を計算する最小の回答者数です
+const minRespondents = 3
+
+// Reliability は回答者ごとの回答から次元ごとのα係数・ω係数と項目統計量を求めます。
+// 逆転項目は検査の採点ルールで反転してから計算します
+func Reliability(inst *models.Instrument, responseSets [][]models.Response) ReliabilityReport {
+	report := ReliabilityReport{
+		InstrumentID: inst.ID,
+		Respondents:  len(responseSets),
+	}
+
+	answers := make([]map[int]int, len(responseSets))
+	for n, responses := range responseSets {
+		answers[n] = make(map[int]int, len(responses))
+		for _, r := range responses {
+			answers[n][r.QuestionID] = r.Score
+		}
+	}
+
+	for _, dimension := range inst.DimensionIDs() {
+		report.Dimensions = append(report.Dimensions, dimensionReliability(inst, dimension, answers))
+	}
+	return report
+}
+
+// dimensionReliability は1つの次元の統計量を求めます
+func dimensionReliability(inst *models.Instrument, dimension string, answers []map[int]int) DimensionReliability {
+	questions := inst.Bank().ByCategory(dimension)
+
+	// 次元のすべての項目に回答した回答者のみを使う
+	items := make([][]float64, len(questions))
+	for _, answer := range answers {
+		complete := true
+		for _, q := range questions {
+			if _, ok := answer[q.ID]; !ok {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			continue
+		}
+		for i, q := range questions {
+			items[i] = append(items[i], inst.KeyedScore(q, answer[q.ID]))
+		}
+	}
+
+	result := DimensionReliability{Dimension: dimension, Items: make([]ItemReliability, len(questions))}
+	if len(questions) > 0 {
+		result.N = len(items[0])
+	}
+	for i, q := range questions {
+		result.Items[i] = ItemReliability{QuestionID: q.ID}
+	}
+	if result.N < minRespondents {
+		return result
+	}
+
+	result.Alpha = Round3(cronbachAlpha(items))
+	result.Omega = Round3(mcDonaldOmega(items))
+
+	totals := sumColumns(items)
+	for i := range questions {
+		stats := &result.Items[i]
+		stats.Mean = Round3(mean(items[i]))
+		stats.SD = Round3(math.Sqrt(variance(items[i])))
+
+		rest := make([]float64, len(totals))
+		for n := range totals {
+			rest[n] = totals[n] - items[i][n]
+		}
+		stats.ItemTotalCorrelation = Round3(Correlation(items[i], rest))
+
+		others := make([][]float64, 0, len(items)-1)
+		others = append(others, items[:i]...)
+		others = append(others, items[i+1:]...)
+		stats.AlphaIfDeleted = Round3(cronbachAlpha(others))
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"hpcs/models"
+	"math"
+	"testing"
+)
+
+func almostEqual(a *float64, b, tolerance float64) bool {
+	return a != nil && math.Abs(*a-b) <= tolerance
+}
+
+// testInstrument は4項目（うち1項目は逆転項目）の次元を持つ検査です
+func testInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	inst, err := models.ParseInstrument([]byte(`
+id: test
+scale: {min: 1, max: 5}
+dimensions:
+  - id: a
+  - id: b
+items:
+  - {id: 1, category: a}
+  - {id: 2, category: a}
+  - {id: 3, category: a}
+  - {id: 4, category: a, isReverse: true}
+  - {id: 5, category: b}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	return inst
+}
+
+func TestReliability(t *testing.T) {
+	inst := testInstrument(t)
+
+	// 反転後の得点
+	keyed := [][]int{
+		{4, 5, 4, 3},
+		{2, 2, 3, 2},
+		{5, 4, 5, 5},
+		{3, 3, 2, 3},
+		{1, 2, 1, 2},
+		{4, 4, 5, 4},
+	}
+	var responseSets [][]models.Response
+	for _, row := range keyed {
+		responseSets = append(responseSets, []models.Response{
+			{QuestionID: 1, Score: row[0]},
+			{QuestionID: 2, Score: row[1]},
+			{QuestionID: 3, Score: row[2]},
+			{QuestionID: 4, Score: 6 - row[3]}, // 逆転項目として回答
+			{QuestionID: 5, Score: 3},
+		})
+	}
+	// 項目が欠けている回答者はリストワイズ除去される
+	responseSets = append(responseSets, []models.Response{{QuestionID: 1, Score: 5}})
+
+	report := Reliability(inst, responseSets)
+	if report.Respondents != 7 || len(report.Dimensions) != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	a := report.Dimensions[0]
+	if a.N != 6 {
+		t.Errorf("Expected 6 complete cases, got %d", a.N)
+	}
+	if !almostEqual(a.Alpha, 0.940, 0.001) {
+		t.Errorf("Expected alpha 0.940, got %v", a.Alpha)
+	}
+	if a.Omega == nil || *a.Omega < *a.Alpha-0.01 || *a.Omega > 1 {
+		t.Errorf("Expected omega between alpha and 1, got %v", a.Omega)
+	}
+
+	expected := []struct {
+		itemTotal      float64
+		alphaIfDeleted float64
+	}{
+		{0.972, 0.883},
+		{0.796, 0.942},
+		{0.870, 0.926},
+		{0.848, 0.930},
+	}
+	for i, e := range expected {
+		item := a.Items[i]
+		if !almostEqual(item.ItemTotalCorrelation, e.itemTotal, 0.001) {
+			t.Errorf("Item %d: expected item-total correlation %f, got %v", item.QuestionID, e.itemTotal, item.ItemTotalCorrelation)
+		}
+		if !almostEqual(item.AlphaIfDeleted, e.alphaIfDeleted, 0.001) {
+			t.Errorf("Item %d: expected alpha if deleted %f, got %v", item.QuestionID, e.alphaIfDeleted, item.AlphaIfDeleted)
+		}
+	}
+
+	// 1項目のみ・分散0の次元は計算できない
+	b := report.Dimensions[1]
+	if b.Alpha != nil || b.Omega != nil || b.Items[0].ItemTotalCorrelation != nil {
+		t.Errorf("Expected undefined statistics for single-item dimension, got %+v", b)
+	}
+	if !almostEqual(b.Items[0].Mean, 3, 0.001) {
+		t.Errorf("Expected item mean 3, got %v", b.Items[0].Mean)
+	}
+}
+
+func TestReliabilityTooFewRespondents(t *testing.T) {
+	inst := testInstrument(t)
+	report := Reliability(inst, [][]models.Response{
+		{{QuestionID: 1, Score: 1}, {QuestionID: 2, Score: 2}, {QuestionID: 3, Score: 3}, {QuestionID: 4, Score: 4}},
+	})
+
+	if a := report.Dimensions[0]; a.N != 1 || a.Alpha != nil || a.Items[0].Mean != nil {
+		t.Errorf("Expected no statistics for a single respondent, got %+v", a)
+	}
+}
+
+func TestMcDonaldOmegaParallelItems(t *testing.T) {
+	// 真値 + 誤差の平行測定では ω と α はほぼ一致する
+	items := [][]float64{
+		{1, 2, 3, 4, 5, 2, 3, 4},
+		{2, 2, 3, 5, 5, 1, 3, 4},
+		{1, 3, 3, 4, 4, 2, 4, 4},
+	}
+	alpha := cronbachAlpha(items)
+	omega := mcDonaldOmega(items)
+	if math.Abs(alpha-omega) > 0.05 {
+		t.Errorf("Expected omega (%f) to be close to alpha (%f)", omega, alpha)
+	}
+}
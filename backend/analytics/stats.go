@@ -0,0 +1,205 @@
+package analytics
+
+import "math"
+
+// mean は平均を求めます
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// variance は不偏分散を求めます
+func variance(values []float64) float64 {
+	m := mean(values)
+	var ss float64
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	return ss / float64(len(values)-1)
+}
+
+// Correlation はピアソンの積率相関係数を求めます。分散が0の場合は NaN を返します
+func Correlation(x, y []float64) float64 {
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// cronbachAlpha は項目ごとの得点（items[項目][回答者]）からα係数を求めます
+func cronbachAlpha(items [][]float64) float64 {
+	k := len(items)
+	if k < 2 {
+		return math.NaN()
+	}
+
+	totals := sumColumns(items)
+	var itemVariance float64
+	for _, item := range items {
+		itemVariance += variance(item)
+	}
+	totalVariance := variance(totals)
+	if totalVariance == 0 {
+		return math.NaN()
+	}
+	return float64(k) / float64(k-1) * (1 - itemVariance/totalVariance)
+}
+
+// mcDonaldOmega は1因子モデルの因子負荷量からω係数を求めます
+func mcDonaldOmega(items [][]float64) float64 {
+	k := len(items)
+	if k < 2 {
+		return math.NaN()
+	}
+
+	r := make([][]float64, k)
+	for i := range items {
+		r[i] = make([]float64, k)
+		for j := range items {
+			if i == j {
+				r[i][j] = 1
+				continue
+			}
+			r[i][j] = Correlation(items[i], items[j])
+			if math.IsNaN(r[i][j]) {
+				return math.NaN()
+			}
+		}
+	}
+
+	loadings := principalAxisLoadings(r)
+	var sumLoadings, uniqueness float64
+	for _, l := range loadings {
+		sumLoadings += l
+		uniqueness += 1 - l*l
+	}
+	common := sumLoadings * sumLoadings
+	return common / (common + uniqueness)
+}
+
+// principalAxisLoadings は主因子法で第1因子の因子負荷量を推定します
+func principalAxisLoadings(r [][]float64) []float64 {
+	k := len(r)
+
+	// 共通性の初期値は各項目の他項目との相関の最大値
+	communalities := make([]float64, k)
+	for i := range r {
+		for j := range r {
+			if i != j && math.Abs(r[i][j]) > communalities[i] {
+				communalities[i] = math.Abs(r[i][j])
+			}
+		}
+	}
+
+	loadings := make([]float64, k)
+	reduced := make([][]float64, k)
+	for i := range reduced {
+		reduced[i] = make([]float64, k)
+		copy(reduced[i], r[i])
+	}
+
+	for iter := 0; iter < 100; iter++ {
+		for i := range reduced {
+			reduced[i][i] = communalities[i]
+		}
+		vector, value := dominantEigen(reduced)
+		if value <= 0 {
+			break
+		}
+
+		var change float64
+		for i := range loadings {
+			loadings[i] = vector[i] * math.Sqrt(value)
+			h := math.Min(loadings[i]*loadings[i], 0.995)
+			change = math.Max(change, math.Abs(h-communalities[i]))
+			communalities[i] = h
+		}
+		if change < 1e-6 {
+			break
+		}
+	}
+
+	// 因子の向きは負荷量の合計が正になるように揃える
+	var sum float64
+	for _, l := range loadings {
+		sum += l
+	}
+	if sum < 0 {
+		for i := range loadings {
+			loadings[i] = -loadings[i]
+		}
+	}
+	return loadings
+}
+
+// dominantEigen はべき乗法で最大固有値と固有ベクトルを求めます
+func dominantEigen(m [][]float64) ([]float64, float64) {
+	k := len(m)
+	vector := make([]float64, k)
+	for i := range vector {
+		vector[i] = 1 / math.Sqrt(float64(k))
+	}
+
+	var value float64
+	next := make([]float64, k)
+	for iter := 0; iter < 1000; iter++ {
+		for i := range m {
+			next[i] = 0
+			for j := range m {
+				next[i] += m[i][j] * vector[j]
+			}
+		}
+
+		var norm float64
+		for _, v := range next {
+			norm += v * v
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			return vector, 0
+		}
+
+		var diff float64
+		for i := range vector {
+			v := next[i] / norm
+			diff = math.Max(diff, math.Abs(v-vector[i]))
+			vector[i] = v
+		}
+		value = norm
+		if diff < 1e-10 {
+			break
+		}
+	}
+	return vector, value
+}
+
+// sumColumns は回答者ごとの合計点を求めます
+func sumColumns(items [][]float64) []float64 {
+	totals := make([]float64, len(items[0]))
+	for _, item := range items {
+		for n, v := range item {
+			totals[n] += v
+		}
+	}
+	return totals
+}
+
+// Round3 は小数点以下3桁に丸めます。NaN や無限大の場合は nil を返します
+func Round3(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	r := math.Round(v*1000) / 1000
+	return &r
+}
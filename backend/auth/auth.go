@@ -0,0 +1,145 @@
+// Package auth はパスワードのハッシュ化と、JWT によるアクセストークン・リフレッシュトークンの発行と検証を行います
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// トークンの種類
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+// トークンの既定の有効期間
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 7 * 24 * time.Hour
+)
+
+// パスワードの長さの制限。bcrypt は72バイトを超える部分を扱えません
+const (
+	MinPasswordLength = 8
+	MaxPasswordBytes  = 72
+)
+
+// issuer はトークンの発行者（iss）です
+const issuer = "hpcs"
+
+// ErrInvalidToken はトークンが不正、期限切れ、または種類が異なることを表します
+var ErrInvalidToken = errors.New("invalid token")
+
+// HashPassword はパスワードを bcrypt でハッシュ化します
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword はパスワードがハッシュと一致するかどうかを返します
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Claims はトークンに含める情報です。ユーザーIDは Subject に入ります
+type Claims struct {
+	// Type はトークンの種類（access または refresh）です
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair はログイン時などに発行するトークンの組です
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	// ExpiresIn はアクセストークンの有効期間（秒）です
+	ExpiresIn int `json:"expiresIn"`
+}
+
+// Issuer は HS256 で署名したトークンを発行し、検証します
+type Issuer struct {
+	secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// now は現在時刻を返します（テストで差し替えます）
+	now func() time.Time
+}
+
+// NewIssuer は署名の鍵から Issuer を作成します。鍵は32バイト以上を推奨します
+func NewIssuer(secret []byte) (*Issuer, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("token signing secret is empty")
+	}
+	return &Issuer{
+		secret:     secret,
+		AccessTTL:  DefaultAccessTTL,
+		RefreshTTL: DefaultRefreshTTL,
+		now:        time.Now,
+	}, nil
+}
+
+// Issue はユーザーのアクセストークンとリフレッシュトークンを発行します
+func (i *Issuer) Issue(userID string) (TokenPair, error) {
+	access, err := i.sign(userID, TokenAccess, i.AccessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := i.sign(userID, TokenRefresh, i.RefreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(i.AccessTTL.Seconds()),
+	}, nil
+}
+
+// sign は指定した種類と有効期間のトークンを作成します
+func (i *Issuer) sign(userID, kind string, ttl time.Duration) (string, error) {
+	now := i.now()
+	claims := Claims{
+		Type: kind,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, nil
+}
+
+// Verify はトークンの署名、有効期限と種類を検証し、ユーザーIDを返します。
+// 検証に失敗した場合は ErrInvalidToken を返します
+func (i *Issuer) Verify(token, kind string) (string, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return i.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(i.now),
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Type != kind || claims.Subject == "" {
+		return "", fmt.Errorf("%w: expected %s token", ErrInvalidToken, kind)
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	if hash == "correct horse" {
+		t.Fatal("Expected password to be hashed")
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("Expected password to match")
+	}
+	if CheckPassword(hash, "wrong horse") {
+		t.Error("Expected wrong password not to match")
+	}
+}
+
+func TestIssuer(t *testing.T) {
+	issuer, err := NewIssuer([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	issuer.now = func() time.Time { return now }
+
+	pair, err := issuer.Issue("user-1")
+	if err != nil {
+		t.Fatalf("Failed to issue tokens: %v", err)
+	}
+	if pair.TokenType != "Bearer" || pair.ExpiresIn != int(DefaultAccessTTL.Seconds()) {
+		t.Errorf("Unexpected token pair: %+v", pair)
+	}
+
+	other, _ := NewIssuer([]byte("other-secret"))
+	other.now = issuer.now
+
+	tests := []struct {
+		name    string
+		issuer  *Issuer
+		token   string
+		kind    string
+		elapsed time.Duration
+		valid   bool
+	}{
+		{name: "アクセストークン", issuer: issuer, token: pair.AccessToken, kind: TokenAccess, valid: true},
+		{name: "リフレッシュトークン", issuer: issuer, token: pair.RefreshToken, kind: TokenRefresh, valid: true},
+		{name: "種類が異なる", issuer: issuer, token: pair.RefreshToken, kind: TokenAccess},
+		{name: "期限切れ", issuer: issuer, token: pair.AccessToken, kind: TokenAccess, elapsed: DefaultAccessTTL + time.Second},
+		{name: "リフレッシュトークンは期限内", issuer: issuer, token: pair.RefreshToken, kind: TokenRefresh, elapsed: 24 * time.Hour, valid: true},
+		{name: "署名の鍵が異なる", issuer: other, token: pair.AccessToken, kind: TokenAccess},
+		{name: "不正な形式", issuer: issuer, token: "not-a-token", kind: TokenAccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC).Add(tt.elapsed)
+			id, err := tt.issuer.Verify(tt.token, tt.kind)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Expected ErrInvalidToken, got %v", err)
+				}
+				return
+			}
+			if err != nil || id != "user-1" {
+				t.Errorf("Expected user-1, got %q (%v)", id, err)
+			}
+		})
+	}
+
+	if _, err := NewIssuer(nil); err == nil {
+		t.Error("Expected error for empty secret")
+	}
+}
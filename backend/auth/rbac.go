@@ -0,0 +1,90 @@
+package auth
+
+import "hpcs/models"
+
+// Principal は認可の判定に使う利用者の権限です
+type Principal struct {
+	UserID string
+	// Superadmin はシステム全体の管理者かどうかです
+	Superadmin bool
+	// Memberships は利用者の組織とチームへの所属です
+	Memberships []models.Membership
+}
+
+// IsOrgMember は組織またはそのチームに所属しているかどうかを返します
+func (p Principal) IsOrgMember(organizationID string) bool {
+	if p.Superadmin {
+		return true
+	}
+	for _, m := range p.Memberships {
+		if m.OrganizationID == organizationID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOrgAdmin は組織を管理できるかどうかを返します
+func (p Principal) IsOrgAdmin(organizationID string) bool {
+	if p.Superadmin {
+		return true
+	}
+	for _, m := range p.Memberships {
+		if m.OrganizationID == organizationID && m.TeamID == "" && m.Role == models.RoleOrgAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTeamMember はチームに所属しているかどうかを返します
+func (p Principal) IsTeamMember(team models.Team) bool {
+	if p.IsOrgAdmin(team.OrganizationID) {
+		return true
+	}
+	for _, m := range p.Memberships {
+		if m.TeamID == team.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageTeam はチームのマネージャーまたは組織の管理者として、チームのメンバーの診断結果を参照できるかどうかを返します
+func (p Principal) CanManageTeam(team models.Team) bool {
+	if p.IsOrgAdmin(team.OrganizationID) {
+		return true
+	}
+	for _, m := range p.Memberships {
+		if m.TeamID == team.ID && m.Role == models.RoleManager {
+			return true
+		}
+	}
+	return false
+}
+
+// CanView は所属が memberships の利用者の診断結果を参照できるかどうかを返します。
+// 参照できるのは、その利用者が所属するチームのマネージャーと、所属する組織の管理者です
+func (p Principal) CanView(memberships []models.Membership) bool {
+	if p.Superadmin {
+		return true
+	}
+	for _, m := range memberships {
+		if p.IsOrgAdmin(m.OrganizationID) {
+			return true
+		}
+		if m.TeamID != "" && p.CanManageTeam(models.Team{ID: m.TeamID, OrganizationID: m.OrganizationID}) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageCampaign は実施を管理し、その診断結果を参照できるかどうかを返します。
+// チーム単位の実施はチームのマネージャーと組織の管理者、組織全体の実施は組織の管理者が管理できます
+func (p Principal) CanManageCampaign(campaign models.Campaign) bool {
+	if campaign.TeamID != "" {
+		return p.CanManageTeam(models.Team{ID: campaign.TeamID, OrganizationID: campaign.OrganizationID})
+	}
+	return p.IsOrgAdmin(campaign.OrganizationID)
+}
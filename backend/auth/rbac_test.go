@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"hpcs/models"
+	"testing"
+)
+
+func TestPrincipal(t *testing.T) {
+	sales := models.Team{ID: "sales", OrganizationID: "acme"}
+	dev := models.Team{ID: "dev", OrganizationID: "acme"}
+	other := models.Team{ID: "other", OrganizationID: "globex"}
+
+	manager := Principal{UserID: "m", Memberships: []models.Membership{
+		{OrganizationID: "acme", TeamID: "sales", UserID: "m", Role: models.RoleManager},
+	}}
+	admin := Principal{UserID: "a", Memberships: []models.Membership{
+		{OrganizationID: "acme", UserID: "a", Role: models.RoleOrgAdmin},
+	}}
+	participant := Principal{UserID: "p", Memberships: []models.Membership{
+		{OrganizationID: "acme", TeamID: "sales", UserID: "p", Role: models.RoleParticipant},
+	}}
+	superadmin := Principal{UserID: "s", Superadmin: true}
+
+	// 診断結果の所有者の所属
+	salesMember := []models.Membership{{OrganizationID: "acme", TeamID: "sales", UserID: "x", Role: models.RoleParticipant}}
+	devMember := []models.Membership{{OrganizationID: "acme", TeamID: "dev", UserID: "y", Role: models.RoleParticipant}}
+	otherMember := []models.Membership{{OrganizationID: "globex", TeamID: "other", UserID: "z", Role: models.RoleParticipant}}
+
+	tests := []struct {
+		name     string
+		got      bool
+		expected bool
+	}{
+		{name: "マネージャーは自分のチームを管理できる", got: manager.CanManageTeam(sales), expected: true},
+		{name: "マネージャーは他のチームを管理できない", got: manager.CanManageTeam(dev), expected: false},
+		{name: "マネージャーは自分のチームのメンバーを参照できる", got: manager.CanView(salesMember), expected: true},
+		{name: "マネージャーは他のチームのメンバーを参照できない", got: manager.CanView(devMember), expected: false},
+		{name: "マネージャーは組織の管理者ではない", got: manager.IsOrgAdmin("acme"), expected: false},
+		{name: "マネージャーは組織のメンバー", got: manager.IsOrgMember("acme"), expected: true},
+		{name: "組織の管理者は組織のすべてのチームを管理できる", got: admin.CanManageTeam(dev), expected: true},
+		{name: "組織の管理者は組織のメンバーを参照できる", got: admin.CanView(devMember), expected: true},
+		{name: "組織の管理者は他の組織のメンバーを参照できない", got: admin.CanView(otherMember), expected: false},
+		{name: "組織の管理者は他の組織のチームを管理できない", got: admin.CanManageTeam(other), expected: false},
+		{name: "参加者はチームのメンバー", got: participant.IsTeamMember(sales), expected: true},
+		{name: "参加者はチームを管理できない", got: participant.CanManageTeam(sales), expected: false},
+		{name: "参加者は同じチームのメンバーを参照できない", got: participant.CanView(salesMember), expected: false},
+		{name: "システム全体の管理者はすべてを参照できる", got: superadmin.CanView(otherMember), expected: true},
+		{name: "システム全体の管理者はすべての組織を管理できる", got: superadmin.IsOrgAdmin("globex"), expected: true},
+		{name: "マネージャーは自分のチームの実施を管理できる", got: manager.CanManageCampaign(models.Campaign{OrganizationID: "acme", TeamID: "sales"}), expected: true},
+		{name: "マネージャーは組織全体の実施を管理できない", got: manager.CanManageCampaign(models.Campaign{OrganizationID: "acme"}), expected: false},
+		{name: "組織の管理者は組織全体の実施を管理できる", got: admin.CanManageCampaign(models.Campaign{OrganizationID: "acme"}), expected: true},
+		{name: "所属のない利用者は参照できない", got: Principal{UserID: "n"}.CanView(salesMember), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, tt.got)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// Package chart は診断結果のレーダーチャートを SVG と PNG で描画します
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font/basicfont"
+)
+
+// 画像の大きさの範囲（ピクセル）
+const (
+	DefaultSize = 400
+	MinSize     = 100
+	MaxSize     = 2000
+)
+
+// Theme はチャートの配色です
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Grid       color.RGBA
+	Text       color.RGBA
+	// Series は系列ごとの色です（系列が多い場合は繰り返して使います）
+	Series []color.RGBA
+}
+
+// 配色
+var (
+	Light = Theme{
+		Name:       "light",
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Grid:       color.RGBA{0xd1, 0xd5, 0xdb, 0xff},
+		Text:       color.RGBA{0x11, 0x18, 0x27, 0xff},
+		Series: []color.RGBA{
+			{0x4f, 0x46, 0xe5, 0xff},
+			{0xf5, 0x9e, 0x0b, 0xff},
+			{0x10, 0xb9, 0x81, 0xff},
+		},
+	}
+	Dark = Theme{
+		Name:       "dark",
+		Background: color.RGBA{0x11, 0x18, 0x27, 0xff},
+		Grid:       color.RGBA{0x4b, 0x55, 0x63, 0xff},
+		Text:       color.RGBA{0xf9, 0xfa, 0xfb, 0xff},
+		Series: []color.RGBA{
+			{0x81, 0x8c, 0xf8, 0xff},
+			{0xfb, 0xbf, 0x24, 0xff},
+			{0x34, 0xd3, 0x99, 0xff},
+		},
+	}
+)
+
+// ThemeByName は名前に対応する配色を返します
+func ThemeByName(name string) (Theme, bool) {
+	for _, theme := range []Theme{Light, Dark} {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Series はチャートに描く1つのプロフィールです
+type Series struct {
+	Name string
+	// Values は軸ごとの尺度上の位置（0〜1）です。nil は算出されなかった次元で、中心として描きます
+	Values []*float64
+	// Dashed は比較用の系列を破線で描くことを表します
+	Dashed bool
+}
+
+// Radar はレーダーチャートの内容です
+type Radar struct {
+	// Axes は軸（次元）の名前です
+	Axes []string
+	// Levels は目盛りの数です（尺度の段階数）
+	Levels int
+	Series []Series
+	// Size は画像の幅と高さ（ピクセル）です
+	Size  int
+	Theme Theme
+}
+
+// Vertex は n 本の軸のうち i 番目の軸上で中心から length の位置を返します。最初の軸は真上です
+func Vertex(i, n int, cx, cy, length float64) (float64, float64) {
+	angle := -math.Pi/2 + 2*math.Pi*float64(i)/float64(n)
+	return cx + length*math.Cos(angle), cy + length*math.Sin(angle)
+}
+
+// layout はチャートの寸法です
+type layout struct {
+	size, cx, cy, radius, labelOffset, fontSize, legendY float64
+}
+
+// layout は画像の大きさから各部の寸法を決めます。下部に凡例の領域を残します
+func (r Radar) layout() layout {
+	size := float64(r.Size)
+	return layout{
+		size:        size,
+		cx:          size / 2,
+		cy:          size * 0.46,
+		radius:      size * 0.30,
+		labelOffset: size * 0.05,
+		fontSize:    math.Max(10, size/30),
+		legendY:     size * 0.92,
+	}
+}
+
+// seriesColor は i 番目の系列の色を返します
+func (r Radar) seriesColor(i int) color.RGBA {
+	return r.Theme.Series[i%len(r.Theme.Series)]
+}
+
+// points は系列の各軸上の頂点を返します
+func (r Radar) points(l layout, s Series) [][2]float64 {
+	points := make([][2]float64, len(r.Axes))
+	for i := range r.Axes {
+		length := 0.0
+		if i < len(s.Values) && s.Values[i] != nil {
+			length = l.radius * math.Max(0, math.Min(1, *s.Values[i]))
+		}
+		x, y := Vertex(i, len(r.Axes), l.cx, l.cy, length)
+		points[i] = [2]float64{x, y}
+	}
+	return points
+}
+
+// labelAnchor は軸の名前の水平方向の揃え位置を返します（0: 左揃え、0.5: 中央、1: 右揃え）
+func labelAnchor(x, cx float64) float64 {
+	switch {
+	case x > cx+1:
+		return 0
+	case x < cx-1:
+		return 1
+	}
+	return 0.5
+}
+
+// hex は色を #rrggbb 形式で返します
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// escape は SVG のテキストとして安全な文字列を返します
+var escape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace
+
+// SVG はチャートを SVG で書き出します
+func (r Radar) SVG(w io.Writer) error {
+	l := r.layout()
+	n := len(r.Axes)
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		r.Size, r.Size, r.Size, r.Size)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(r.Theme.Background))
+
+	// 目盛りと軸
+	for level := 1; level <= r.Levels; level++ {
+		ring := make([]string, n)
+		for i := range ring {
+			x, y := Vertex(i, n, l.cx, l.cy, l.radius*float64(level)/float64(r.Levels))
+			ring[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		fmt.Fprintf(&b, `<polygon points="%s" fill="none" stroke="%s" stroke-width="1"/>`+"\n", strings.Join(ring, " "), hex(r.Theme.Grid))
+	}
+	for i := 0; i < n; i++ {
+		x, y := Vertex(i, n, l.cx, l.cy, l.radius)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="1"/>`+"\n", l.cx, l.cy, x, y, hex(r.Theme.Grid))
+	}
+
+	// 系列
+	for i, s := range r.Series {
+		points := r.points(l, s)
+		coords := make([]string, len(points))
+		for j, p := range points {
+			coords[j] = fmt.Sprintf("%.1f,%.1f", p[0], p[1])
+		}
+		dash := ""
+		if s.Dashed {
+			dash = ` stroke-dasharray="6 4"`
+		}
+		fmt.Fprintf(&b, `<polygon points="%s" fill="%s" fill-opacity="0.25" stroke="%s" stroke-width="2"%s/>`+"\n",
+			strings.Join(coords, " "), hex(r.seriesColor(i)), hex(r.seriesColor(i)), dash)
+	}
+
+	// 軸の名前
+	anchors := map[float64]string{0: "start", 0.5: "middle", 1: "end"}
+	for i, axis := range r.Axes {
+		x, y := Vertex(i, n, l.cx, l.cy, l.radius+l.labelOffset)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="%.0f" fill="%s" text-anchor="%s" dominant-baseline="middle">%s</text>`+"\n",
+			x, y, l.fontSize, hex(r.Theme.Text), anchors[labelAnchor(x, l.cx)], escape(axis))
+	}
+
+	// 凡例
+	x := l.size * 0.05
+	for i, s := range r.Series {
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.0f" height="%.0f" fill="%s"/>`+"\n",
+			x, l.legendY-l.fontSize/2, l.fontSize, l.fontSize, hex(r.seriesColor(i)))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="%.0f" fill="%s" dominant-baseline="middle">%s</text>`+"\n",
+			x+l.fontSize*1.4, l.legendY, l.fontSize, hex(r.Theme.Text), escape(s.Name))
+		x += l.fontSize*2.4 + float64(len([]rune(s.Name)))*l.fontSize*0.6
+	}
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PNG はチャートを PNG で書き出します。fontPath が空の場合は ASCII のみ表示できる組み込みフォントを使います
+func (r Radar) PNG(w io.Writer, fontPath string) error {
+	l := r.layout()
+	n := len(r.Axes)
+	dc := gg.NewContext(r.Size, r.Size)
+
+	dc.SetColor(r.Theme.Background)
+	dc.Clear()
+
+	// 目盛りと軸
+	dc.SetColor(r.Theme.Grid)
+	dc.SetLineWidth(1)
+	for level := 1; level <= r.Levels; level++ {
+		for i := 0; i < n; i++ {
+			dc.LineTo(Vertex(i, n, l.cx, l.cy, l.radius*float64(level)/float64(r.Levels)))
+		}
+		dc.ClosePath()
+		dc.Stroke()
+	}
+	for i := 0; i < n; i++ {
+		dc.MoveTo(l.cx, l.cy)
+		dc.LineTo(Vertex(i, n, l.cx, l.cy, l.radius))
+		dc.Stroke()
+	}
+
+	// 系列
+	for i, s := range r.Series {
+		c := r.seriesColor(i)
+		for _, p := range r.points(l, s) {
+			dc.LineTo(p[0], p[1])
+		}
+		dc.ClosePath()
+		dc.SetRGBA255(int(c.R), int(c.G), int(c.B), 64)
+		dc.FillPreserve()
+		dc.SetColor(c)
+		dc.SetLineWidth(2)
+		if s.Dashed {
+			dc.SetDash(6, 4)
+		}
+		dc.Stroke()
+		dc.SetDash()
+	}
+
+	// 軸の名前と凡例
+	if fontPath != "" {
+		if err := dc.LoadFontFace(fontPath, l.fontSize*0.75); err != nil {
+			return fmt.Errorf("failed to load chart font: %w", err)
+		}
+	} else {
+		dc.SetFontFace(basicfont.Face7x13)
+	}
+	dc.SetColor(r.Theme.Text)
+	for i, axis := range r.Axes {
+		x, y := Vertex(i, n, l.cx, l.cy, l.radius+l.labelOffset)
+		dc.DrawStringAnchored(axis, x, y, labelAnchor(x, l.cx), 0.35)
+	}
+	x := l.size * 0.05
+	for i, s := range r.Series {
+		dc.SetColor(r.seriesColor(i))
+		dc.DrawRectangle(x, l.legendY-l.fontSize/2, l.fontSize, l.fontSize)
+		dc.Fill()
+		dc.SetColor(r.Theme.Text)
+		dc.DrawStringAnchored(s.Name, x+l.fontSize*1.4, l.legendY, 0, 0.35)
+		width, _ := dc.MeasureString(s.Name)
+		x += l.fontSize*2.4 + width
+	}
+
+	return dc.EncodePNG(w)
+}
@@ -0,0 +1,102 @@
+package chart
+
+import (
+	"bytes"
+	"hpcs/models"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func testRadar() Radar {
+	return Radar{
+		Axes:   []string{"神経症傾向", "Extraversion", "C", "A", "O"},
+		Levels: 4,
+		Series: []Series{
+			{Name: "This result", Values: []*float64{models.Float64(0.5), models.Float64(1), nil, models.Float64(0.25), models.Float64(2)}},
+			{Name: "Norm mean", Values: []*float64{models.Float64(0.5), models.Float64(0.5), models.Float64(0.5), models.Float64(0.5), models.Float64(0.5)}, Dashed: true},
+		},
+		Size:  300,
+		Theme: Dark,
+	}
+}
+
+func TestVertex(t *testing.T) {
+	// 最初の軸は真上、5軸なら2番目の軸は右上
+	x, y := Vertex(0, 5, 100, 100, 50)
+	if math.Abs(x-100) > 1e-9 || math.Abs(y-50) > 1e-9 {
+		t.Errorf("Expected first vertex at (100, 50), got (%f, %f)", x, y)
+	}
+	x, y = Vertex(1, 5, 100, 100, 50)
+	if x <= 100 || y >= 100 {
+		t.Errorf("Expected second vertex in the upper right, got (%f, %f)", x, y)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testRadar().SVG(&buf); err != nil {
+		t.Fatalf("Failed to render SVG: %v", err)
+	}
+	svg := buf.String()
+
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300"`,
+		`fill="#111827"`,
+		">神経症傾向</text>",
+		`stroke-dasharray="6 4"`,
+		">Norm mean</text>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected SVG to contain %q", want)
+		}
+	}
+	// 目盛り4本と系列2本
+	if got := strings.Count(svg, "<polygon"); got != 6 {
+		t.Errorf("Expected 6 polygons, got %d", got)
+	}
+}
+
+func TestSVGEscapesLabels(t *testing.T) {
+	radar := testRadar()
+	radar.Axes[2] = `<b>&"`
+
+	var buf bytes.Buffer
+	radar.SVG(&buf)
+	if !strings.Contains(buf.String(), ">&lt;b&gt;&amp;&quot;</text>") {
+		t.Error("Expected axis label to be escaped")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testRadar().PNG(&buf, ""); err != nil {
+		t.Fatalf("Failed to render PNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+		t.Errorf("Expected 300x300 image, got %dx%d", b.Dx(), b.Dy())
+	}
+	// 背景は配色の背景色
+	r, g, b, _ := img.At(1, 1).RGBA()
+	if uint8(r>>8) != Dark.Background.R || uint8(g>>8) != Dark.Background.G || uint8(b>>8) != Dark.Background.B {
+		t.Errorf("Unexpected background color: %d %d %d", r>>8, g>>8, b>>8)
+	}
+
+	if err := testRadar().PNG(&buf, "missing.ttf"); err == nil {
+		t.Error("Expected error for missing font")
+	}
+}
+
+func TestThemeByName(t *testing.T) {
+	if theme, ok := ThemeByName("dark"); !ok || theme.Name != "dark" {
+		t.Errorf("Expected dark theme, got %+v", theme)
+	}
+	if _, ok := ThemeByName("neon"); ok {
+		t.Error("Expected unknown theme")
+	}
+}
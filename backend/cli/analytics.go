@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpcs/analytics"
+	"hpcs/data"
+	"hpcs/models"
+	"io"
+	"text/tabwriter"
+)
+
+// runAnalytics は analytics サブコマンドを実行します
+func runAnalytics(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] != "reliability" {
+		return fmt.Errorf("usage: hpcs analytics reliability [flags]")
+	}
+	return runReliability(args[1:], stdout, stderr)
+}
+
+// runReliability は診断結果の回答から信頼性のレポートを出力します
+func runReliability(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("analytics reliability", stderr)
+	dbPath := fs.String("db", "", "読み込むデータベースのパス")
+	inputPath := fs.String("input", "", "読み込むエクスポートファイルのパス（- は標準入力）")
+	instrumentsDir := fs.String("instruments", "", "検査定義のディレクトリ（省略時は同梱の定義）")
+	instrumentID := fs.String("instrument", data.DefaultInstrumentID, "集計する検査のID")
+	format := fs.String("format", "text", "出力形式（text または json）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	instruments, err := data.LoadInstrumentSet(*instrumentsDir, "")
+	if err != nil {
+		return err
+	}
+	inst, ok := instruments.Get(*instrumentID)
+	if !ok {
+		return fmt.Errorf("unknown instrument: %s", *instrumentID)
+	}
+
+	assessments, err := loadAssessments(*dbPath, *inputPath)
+	if err != nil {
+		return err
+	}
+
+	var responseSets [][]models.Response
+	for _, a := range assessments {
+		if a.InstrumentID == inst.ID {
+			responseSets = append(responseSets, a.Responses)
+		}
+	}
+	report := analytics.Reliability(inst, responseSets)
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "text":
+		writeReliabilityText(stdout, report)
+		return nil
+	}
+	return fmt.Errorf("unknown format: %s", *format)
+}
+
+// writeReliabilityText は信頼性のレポートを表形式で出力します
+func writeReliabilityText(w io.Writer, report analytics.ReliabilityReport) {
+	fmt.Fprintf(w, "Instrument: %s (%d respondents)\n", report.InstrumentID, report.Respondents)
+
+	for _, d := range report.Dimensions {
+		fmt.Fprintf(w, "\n%s  n=%d  alpha=%s  omega=%s\n", d.Dimension, d.N, formatStat(d.Alpha), formatStat(d.Omega))
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "item\tmean\tsd\titem-total r\talpha if deleted\t")
+		for _, item := range d.Items {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t\n", item.QuestionID,
+				formatStat(item.Mean), formatStat(item.SD),
+				formatStat(item.ItemTotalCorrelation), formatStat(item.AlphaIfDeleted))
+		}
+		tw.Flush()
+	}
+}
+
+// formatStat は統計量を表示用に整形します。計算できない値は "-" になります
+func formatStat(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", *v)
+}
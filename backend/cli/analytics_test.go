@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"hpcs/analytics"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyticsReliability(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "results.json")
+	content := `[
+		{"instrumentId":"hpcs-74","responses":[{"questionId":5,"score":4},{"questionId":6,"score":5}]},
+		{"instrumentId":"hpcs-74","responses":[{"questionId":5,"score":2},{"questionId":6,"score":1}]},
+		{"instrumentId":"hpcs-74","responses":[{"questionId":5,"score":3},{"questionId":6,"score":3}]}
+	]`
+	if err := os.WriteFile(input, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"analytics", "reliability", "-input", input, "-format", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	var report analytics.ReliabilityReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal report: %v", err)
+	}
+	if report.Respondents != 3 {
+		t.Errorf("Expected 3 respondents, got %d", report.Respondents)
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"analytics", "reliability", "-input", input}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	text := stdout.String()
+	if !strings.Contains(text, "Instrument: hpcs-74 (3 respondents)") || !strings.Contains(text, "item-total r") {
+		t.Errorf("Unexpected text report:\n%s", text)
+	}
+
+	if code := Run([]string{"analytics", "reliability", "-input", input, "-format", "xml"}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 for unknown format, got %d", code)
+	}
+}
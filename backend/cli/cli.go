@@ -0,0 +1,134 @@
+// Package cli はサーバーを起動せずに実行する hpcs のサブコマンドを提供します
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hpcs/models"
+	"hpcs/storage"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: hpcs <command> [arguments]
+
+Commands:
+  serve          APIサーバーを起動します（引数なしの場合も同じ）
+  score          JSON または CSV の回答ファイルを採点します
+  norms build    保存済みの診断結果から規準値ファイルを作成します
+  analytics reliability
+                 尺度の信頼性（α係数・ω係数・項目統計量）を集計します
+`
+
+// Run はサブコマンドを実行し、終了コードを返します
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "score":
+		err = runScore(args[1:], stdout, stderr)
+	case "norms":
+		err = runNorms(args[1:], stdout, stderr)
+	case "analytics":
+		err = runAnalytics(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command: %s\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// newFlagSet はエラー時に終了しないフラグセットを作成します
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// loadAssessments はデータベースまたはエクスポートファイルから診断結果を読み込みます。
+// ファイルは GET /api/results と同じ JSON 配列、または1行1件の JSON Lines 形式です
+func loadAssessments(dbPath, inputPath string) ([]models.Assessment, error) {
+	switch {
+	case dbPath != "" && inputPath != "":
+		return nil, fmt.Errorf("specify either -db or -input, not both")
+	case dbPath != "":
+		s, err := storage.OpenBolt(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+		return s.ListAssessments(storage.AssessmentFilter{})
+	case inputPath != "":
+		f, err := openInput(inputPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return decodeAssessments(f)
+	}
+	return nil, fmt.Errorf("either -db or -input is required")
+}
+
+// openInput はファイルを開きます。"-" の場合は標準入力を使います
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// decodeAssessments は JSON 配列または JSON Lines の診断結果を読み込みます
+func decodeAssessments(r io.Reader) ([]models.Assessment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var assessments []models.Assessment
+		if err := json.Unmarshal(data, &assessments); err != nil {
+			return nil, fmt.Errorf("failed to decode results: %w", err)
+		}
+		return assessments, nil
+	}
+
+	var assessments []models.Assessment
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	for dec.More() {
+		var a models.Assessment
+		if err := dec.Decode(&a); err != nil {
+			return nil, fmt.Errorf("failed to decode results: %w", err)
+		}
+		assessments = append(assessments, a)
+	}
+	return assessments, nil
+}
+
+// createOutput は出力先を開きます。空または "-" の場合は標準出力を使います
+func createOutput(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" || path == "-" {
+		return stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
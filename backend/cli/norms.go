@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpcs/data"
+	"hpcs/norms"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// runNorms は norms サブコマンドを実行します
+func runNorms(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] != "build" {
+		return fmt.Errorf("usage: hpcs norms build [flags]")
+	}
+	return runNormsBuild(args[1:], stdout, stderr)
+}
+
+// runNormsBuild は診断結果から規準値ファイルを作成します
+func runNormsBuild(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("norms build", stderr)
+	dbPath := fs.String("db", "", "読み込むデータベースのパス")
+	inputPath := fs.String("input", "", "読み込むエクスポートファイルのパス（- は標準入力）")
+	instrumentsDir := fs.String("instruments", "", "検査定義のディレクトリ（省略時は同梱の定義）")
+	instrumentID := fs.String("instrument", data.DefaultInstrumentID, "規準値を作成する検査のID")
+	name := fs.String("name", "", "規準値の名前（必須）")
+	description := fs.String("description", "", "規準値の説明")
+	groupBy := fs.String("group-by", "", "属性ごとのグループを作成する（age, gender をカンマ区切りで指定）")
+	minGroupSize := fs.Int("min-group-size", 30, "グループを作成する最小人数")
+	percentiles := fs.String("percentiles", "", "パーセンタイル表の区切り（カンマ区切り、省略時は 1,5,10,...,95,99）")
+	outPath := fs.String("out", "", "出力ファイルのパス（省略時は標準出力）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	instruments, err := data.LoadInstrumentSet(*instrumentsDir, "")
+	if err != nil {
+		return err
+	}
+	inst, ok := instruments.Get(*instrumentID)
+	if !ok {
+		return fmt.Errorf("unknown instrument: %s", *instrumentID)
+	}
+
+	opts := norms.BuildOptions{
+		Name:         *name,
+		InstrumentID: inst.ID,
+		Description:  *description,
+		Dimensions:   inst.DimensionIDs(),
+		MinGroupSize: *minGroupSize,
+	}
+	for _, field := range splitList(*groupBy) {
+		switch field {
+		case "age":
+			opts.GroupByAge = true
+		case "gender":
+			opts.GroupByGender = true
+		default:
+			return fmt.Errorf("unknown -group-by field: %s", field)
+		}
+	}
+	for _, field := range splitList(*percentiles) {
+		p, err := strconv.ParseFloat(field, 64)
+		if err != nil || p < 0 || p > 100 {
+			return fmt.Errorf("invalid percentile: %s", field)
+		}
+		opts.Percentiles = append(opts.Percentiles, p)
+	}
+
+	assessments, err := loadAssessments(*dbPath, *inputPath)
+	if err != nil {
+		return err
+	}
+
+	var samples []norms.Sample
+	for _, a := range assessments {
+		if a.InstrumentID != inst.ID {
+			continue
+		}
+		sample := norms.Sample{Scores: make(map[string]float64, len(opts.Dimensions))}
+		for _, dimension := range opts.Dimensions {
+			if score, ok := a.Result.Get(dimension); ok {
+				sample.Scores[dimension] = score
+			}
+		}
+		if a.Demographics != nil {
+			sample.AgeGroup = a.Demographics.AgeGroup
+			sample.Gender = a.Demographics.Gender
+		}
+		samples = append(samples, sample)
+	}
+
+	set, err := norms.Build(samples, opts)
+	if err != nil {
+		return err
+	}
+
+	out, closeOut, err := createOutput(*outPath, stdout)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		closeOut()
+		return err
+	}
+	if err := closeOut(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "built norm set %s from %d results (%d groups)\n", set.Name, len(samples), len(set.Groups))
+	return nil
+}
+
+// splitList はカンマ区切りの値を分割します
+func splitList(s string) []string {
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
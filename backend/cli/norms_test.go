@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"hpcs/models"
+	"hpcs/norms"
+	"hpcs/storage"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormsBuildFromExport(t *testing.T) {
+	dir := t.TempDir()
+
+	var lines []string
+	for i := 1; i <= 4; i++ {
+		a := models.Assessment{
+			InstrumentID: "hpcs-74",
+			Result:       models.Result{Neuroticism: models.Float64(float64(i)), Openness: models.Float64(3 + float64(i)/2)},
+			Demographics: &models.Demographics{Gender: "female"},
+		}
+		line, _ := json.Marshal(a)
+		lines = append(lines, string(line))
+	}
+	// 他の検査の結果は含まれない
+	lines = append(lines, `{"instrumentId":"short-4","result":{"neuroticism":1}}`)
+
+	input := filepath.Join(dir, "results.jsonl")
+	if err := os.WriteFile(input, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "norms.json")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"norms", "build", "-input", input, "-name", "staff", "-group-by", "gender", "-min-group-size", "2", "-out", out}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	sets, err := norms.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to load built norms: %v", err)
+	}
+	if len(sets) != 1 || sets[0].Name != "staff" || sets[0].InstrumentID != "hpcs-74" {
+		t.Fatalf("Unexpected norm sets: %+v", sets)
+	}
+	if got := sets[0].Dimensions["neuroticism"]; got.N != 4 || got.Mean != 2.5 {
+		t.Errorf("Unexpected neuroticism norm: %+v", got)
+	}
+	if len(sets[0].Groups) != 1 || sets[0].Groups[0].Gender != "female" {
+		t.Errorf("Expected a female group, got %+v", sets[0].Groups)
+	}
+}
+
+func TestNormsBuildFromDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	s, err := storage.OpenBolt(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		s.SaveAssessment(&models.Assessment{InstrumentID: "hpcs-74", Result: models.Result{Openness: models.Float64(float64(i))}})
+	}
+	s.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"norms", "build", "-db", dbPath, "-name", "db"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	set, err := norms.Parse(stdout.Bytes(), "json")
+	if err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if got := set.Dimensions["openness"]; got.N != 3 || got.Mean != 2 {
+		t.Errorf("Unexpected openness norm: %+v", got)
+	}
+}
+
+func TestNormsBuildErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "名前なし", args: []string{"norms", "build", "-input", "x.json"}},
+		{name: "入力なし", args: []string{"norms", "build", "-name", "x"}},
+		{name: "存在しない検査", args: []string{"norms", "build", "-name", "x", "-input", "x.json", "-instrument", "unknown"}},
+		{name: "不正なグループ", args: []string{"norms", "build", "-name", "x", "-input", "x.json", "-group-by", "region"}},
+		{name: "不明なサブコマンド", args: []string{"norms", "show"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := Run(tt.args, &stdout, &stderr); code != 1 {
+				t.Errorf("Expected exit code 1, got %d", code)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"unknown"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for unknown command, got %d", code)
+	}
+}
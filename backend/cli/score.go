@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hpcs/data"
+	"hpcs/dataset"
+	"hpcs/models"
+	"hpcs/norms"
+	"hpcs/quality"
+	"hpcs/scoring"
+	"hpcs/validation"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// scoredRow は1人分の採点結果です。回答に問題がある場合は Errors のみを持ちます
+type scoredRow struct {
+	Row        int               `json:"row"`
+	Respondent string            `json:"respondent,omitempty"`
+	Result     *models.Result    `json:"result,omitempty"`
+	Norms      *norms.Profile    `json:"norms,omitempty"`
+	Quality    *quality.Report   `json:"quality,omitempty"`
+	Errors     validation.Errors `json:"errors,omitempty"`
+}
+
+// runScore はファイルまたは標準入力の回答を採点して出力します。
+// 採点できなかった回答者がいる場合は結果を出力したうえでエラーを返します
+func runScore(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("score", stderr)
+	inputPath := fs.String("input", "-", "読み込む回答ファイルのパス（- は標準入力）")
+	inputFormat := fs.String("input-format", "auto", "入力形式（auto, json または csv）")
+	instrumentsDir := fs.String("instruments", "", "検査定義のディレクトリ（省略時は同梱の定義）")
+	instrumentID := fs.String("instrument", "", "採点する検査のID（省略時は JSON の instrumentId または同梱の標準検査）")
+	normsPath := fs.String("norms", "", "規準参照得点の計算に使う規準値ファイルのパス")
+	format := fs.String("format", "table", "出力形式（table, json または csv）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 && *inputPath == "-" {
+		*inputPath = fs.Arg(0)
+	}
+
+	f, err := openInput(*inputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	input, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	instruments, err := data.LoadInstrumentSet(*instrumentsDir, "")
+	if err != nil {
+		return err
+	}
+
+	var rows []dataset.Row
+	var inst *models.Instrument
+	switch detectInputFormat(*inputFormat, *inputPath, input) {
+	case "json":
+		batch, err := dataset.ReadJSON(bytes.NewReader(input))
+		if err != nil {
+			return fmt.Errorf("failed to decode responses: %w", err)
+		}
+		if *instrumentID == "" {
+			*instrumentID = batch.InstrumentID
+		}
+		if inst, err = lookupInstrument(instruments, *instrumentID); err != nil {
+			return err
+		}
+		rows = batch.Rows()
+	case "csv":
+		if inst, err = lookupInstrument(instruments, *instrumentID); err != nil {
+			return err
+		}
+		if rows, err = dataset.ReadWide(bytes.NewReader(input), inst); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown input format: %s", *inputFormat)
+	}
+
+	var normSet *norms.NormSet
+	if *normsPath != "" {
+		if normSet, err = loadNormSet(*normsPath, inst); err != nil {
+			return err
+		}
+	}
+
+	scorer := scoring.New(inst)
+	results := make([]scoredRow, len(rows))
+	failed := 0
+	for i, row := range rows {
+		results[i] = scoredRow{Row: row.Line, Respondent: row.Respondent}
+		if errs := row.Validate(inst); len(errs) > 0 {
+			results[i].Errors = errs
+			failed++
+			continue
+		}
+		outcome, err := scorer.Score(scoring.Submission{
+			Responses:    row.Responses,
+			Demographics: row.Demographics,
+			NormSet:      normSet,
+		})
+		if err != nil {
+			return err
+		}
+		results[i].Result = &outcome.Result
+		results[i].Norms = outcome.Norms
+		results[i].Quality = &outcome.Quality
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(results)
+	case "csv":
+		err = writeScoresCSV(stdout, inst, normSet != nil, results)
+	case "table":
+		writeScoresTable(stdout, inst, results)
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d respondents could not be scored", failed, len(rows))
+	}
+	return nil
+}
+
+// lookupInstrument は指定したIDの検査定義を返します。IDが空の場合は標準の検査を返します
+func lookupInstrument(instruments *models.InstrumentSet, id string) (*models.Instrument, error) {
+	inst, ok := instruments.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown instrument: %s", id)
+	}
+	return inst, nil
+}
+
+// detectInputFormat は入力形式を返します。auto の場合は拡張子、次に内容の先頭の文字で判定します
+func detectInputFormat(format, path string, input []byte) string {
+	if format != "auto" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	}
+	if trimmed := bytes.TrimSpace(input); len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return "json"
+	}
+	return "csv"
+}
+
+// loadNormSet は規準値ファイルを読み込み、検査に対応していることを確認します
+func loadNormSet(path string, inst *models.Instrument) (*norms.NormSet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := "yaml"
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		format = "json"
+	}
+	set, err := norms.Parse(content, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if set.InstrumentID != inst.ID {
+		return nil, fmt.Errorf("norm set %s is not defined for instrument %s", set.Name, inst.ID)
+	}
+	return set, nil
+}
+
+// writeScoresTable は次元ごとのスコアを表形式で出力します。算出されなかったスコアは "-" になります
+func writeScoresTable(w io.Writer, inst *models.Instrument, results []scoredRow) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := append([]string{"row", "respondent"}, inst.DimensionIDs()...)
+	fmt.Fprintln(tw, strings.Join(append(header, "flags"), "\t"))
+
+	var problems []string
+	for _, r := range results {
+		respondent := r.Respondent
+		if respondent == "" {
+			respondent = "-"
+		}
+		cells := []string{strconv.Itoa(r.Row), respondent}
+		if r.Result == nil {
+			for range inst.DimensionIDs() {
+				cells = append(cells, "-")
+			}
+			cells = append(cells, "invalid")
+			problems = append(problems, fmt.Sprintf("row %d: %s", r.Row, r.Errors.Error()))
+		} else {
+			for _, dimension := range inst.DimensionIDs() {
+				cells = append(cells, formatScore(r.Result.Get(dimension)))
+			}
+			cells = append(cells, strings.Join(r.Quality.Flags, ","))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+
+	if len(problems) > 0 {
+		fmt.Fprintln(w)
+		for _, p := range problems {
+			fmt.Fprintln(w, p)
+		}
+	}
+}
+
+// writeScoresCSV は次元と下位側面のスコアを CSV で出力します。
+// withNorms が true の場合は次元ごとの T 得点とパーセンタイルの列を加えます
+func writeScoresCSV(w io.Writer, inst *models.Instrument, withNorms bool, results []scoredRow) error {
+	header := []string{"row", "respondent"}
+	for _, column := range dataset.Columns(inst, false) {
+		if column.Kind == dataset.KindScore || column.Kind == dataset.KindFacet {
+			header = append(header, column.Name)
+		}
+	}
+	if withNorms {
+		for _, dimension := range inst.DimensionIDs() {
+			header = append(header, dimension+"_t", dimension+"_percentile")
+		}
+	}
+	header = append(header, "qualityFlags", "errors")
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, r := range results {
+		record := []string{strconv.Itoa(r.Row), r.Respondent}
+		for _, d := range inst.Dimensions {
+			record = append(record, csvScore(r.Result, d.ID, ""))
+		}
+		for _, d := range inst.Dimensions {
+			for _, f := range d.Facets {
+				record = append(record, csvScore(r.Result, d.ID, f.ID))
+			}
+		}
+		if withNorms {
+			for _, dimension := range inst.DimensionIDs() {
+				t, percentile := "", ""
+				if r.Norms != nil {
+					if s, ok := r.Norms.Dimensions[dimension]; ok {
+						t, percentile = fmt.Sprintf("%.1f", s.T), fmt.Sprintf("%.1f", s.Percentile)
+					}
+				}
+				record = append(record, t, percentile)
+			}
+		}
+		var flags, errs string
+		if r.Quality != nil {
+			flags = strings.Join(r.Quality.Flags, ";")
+		}
+		if len(r.Errors) > 0 {
+			errs = r.Errors.Error()
+		}
+		cw.Write(append(record, flags, errs))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvScore は次元（facet が空でない場合は下位側面）のスコアを返します。スコアがない場合は空になります
+func csvScore(result *models.Result, dimension, facet string) string {
+	if result == nil {
+		return ""
+	}
+	if facet != "" {
+		score, ok := result.Facets[dimension][facet]
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%.4f", score)
+	}
+	score, ok := result.Get(dimension)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.4f", score)
+}
+
+// formatScore はスコアを表示用に整形します。スコアがない場合は "-" になります
+func formatScore(score float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", score)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	dir := t.TempDir()
+	jsonInput := filepath.Join(dir, "responses.json")
+	if err := os.WriteFile(jsonInput, []byte(`{"respondents":[
+		{"respondent":"a","responses":[{"questionId":1,"score":5},{"questionId":2,"score":5},{"questionId":29,"score":2},{"questionId":30,"score":2},
+			{"questionId":5,"score":4},{"questionId":6,"score":4},{"questionId":7,"score":4},{"questionId":8,"score":4},{"questionId":22,"score":4}]},
+		{"respondent":"b","responses":[{"questionId":5,"score":3},{"questionId":6,"score":3},{"questionId":7,"score":3},{"questionId":8,"score":3},{"questionId":22,"score":3}]}
+	]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	csvInput := filepath.Join(dir, "responses.txt")
+	if err := os.WriteFile(csvInput, []byte("respondent,q1,q2,q29,q30,q5,q6,q7,q8,q22\na,5,5,2,2,4,4,4,4,4\nb,9,,,,x,,,,\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		contains     []string
+	}{
+		{
+			name:         "JSON を表で出力",
+			args:         []string{"-input", jsonInput},
+			expectedCode: 0,
+			contains:     []string{"row  respondent  neuroticism", "1    a           4.50         4.00", "2    b           -            3.00"},
+		},
+		{
+			name:         "CSV を CSV で出力（内容から形式を判定）",
+			args:         []string{"-format", "csv", csvInput},
+			expectedCode: 1,
+			contains: []string{
+				"row,respondent,neuroticism,extraversion,conscientiousness,agreeableness,openness,neuroticism_anxiety,",
+				"1,a,4.5000,4.0000,,,,5.0000,",
+				"invalid value for question 5",
+			},
+		},
+		{
+			name:         "問題のある行は表の下に表示",
+			args:         []string{"-input", csvInput, "-input-format", "csv"},
+			expectedCode: 1,
+			contains:     []string{"2    b           -", "row 2: invalid value for question 5"},
+		},
+		{
+			name:         "存在しない検査",
+			args:         []string{"-input", jsonInput, "-instrument", "unknown"},
+			expectedCode: 1,
+		},
+		{
+			name:         "不明な出力形式",
+			args:         []string{"-input", jsonInput, "-format", "xml"},
+			expectedCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := Run(append([]string{"score"}, tt.args...), &stdout, &stderr); code != tt.expectedCode {
+				t.Fatalf("Expected exit code %d, got %d: %s", tt.expectedCode, code, stderr.String())
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("Expected output to contain %q:\n%s", s, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestScoreJSON(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "responses.json")
+	if err := os.WriteFile(input, []byte(`[{"responses":[{"questionId":1,"score":5},{"questionId":2,"score":5},{"questionId":3,"score":5},{"questionId":4,"score":5}]},{"responses":[{"questionId":999,"score":3}]}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"score", "-input", input, "-format", "json"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "1 of 2 respondents could not be scored") {
+		t.Errorf("Unexpected stderr: %s", stderr.String())
+	}
+
+	var results []scoredRow
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to unmarshal results: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Result == nil || *results[0].Result.Neuroticism != 5 || results[0].Quality == nil {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[1].Result != nil || len(results[1].Errors) != 1 || results[1].Errors[0].Code != "unknown_question" {
+		t.Errorf("Unexpected second result: %+v", results[1])
+	}
+}
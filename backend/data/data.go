@@ -0,0 +1,42 @@
+// Package data はバックエンドに同梱するデフォルトのデータファイルを提供します
+package data
+
+import (
+	"embed"
+	"hpcs/models"
+	"io/fs"
+	"os"
+)
+
+// DefaultInstrumentID は同梱の標準検査のIDです
+const DefaultInstrumentID = "hpcs-74"
+
+//go:embed instruments
+var instruments embed.FS
+
+// Instruments は同梱の検査定義ファイルを返します
+func Instruments() fs.FS {
+	sub, err := fs.Sub(instruments, "instruments")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// LoadInstrumentSet は検査定義を読み込みます。
+// dir が空の場合は同梱の定義を、defaultID が空の場合は同梱の標準検査をデフォルトとして使います
+func LoadInstrumentSet(dir, defaultID string) (*models.InstrumentSet, error) {
+	fsys := Instruments()
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+	if defaultID == "" {
+		defaultID = DefaultInstrumentID
+	}
+
+	list, err := models.LoadInstruments(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewInstrumentSet(list, defaultID)
+}
@@ -0,0 +1,191 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"hpcs/i18n"
+	"hpcs/models"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 書き出す表の列の種類
+const (
+	KindMeta  = "meta"
+	KindItem  = "item"
+	KindScore = "score"
+	KindFacet = "facet"
+)
+
+// Column は書き出す表の1列です。列名は SPSS の変数名として使える英数字と _ のみで構成します
+type Column struct {
+	Name string
+	Kind string
+	// Dimension と Facet は質問や得点が属する次元と下位側面のIDです
+	Dimension string
+	Facet     string
+	// QuestionID は質問の列の場合の質問IDです
+	QuestionID int
+	// Reverse は逆転項目かどうかです
+	Reverse bool
+}
+
+// metaColumns は診断結果の属性の列です
+var metaColumns = []string{ColumnID, "instrumentId", "instrumentVersion", "createdAt", "normSet", ColumnAgeGroup, ColumnGender, "qualityFlags"}
+
+// Columns は検査の診断結果を書き出すときの列を返します。items が true の場合は質問ごとの回答の列を含めます
+func Columns(inst *models.Instrument, items bool) []Column {
+	var columns []Column
+	for _, name := range metaColumns {
+		columns = append(columns, Column{Name: name, Kind: KindMeta})
+	}
+	if items {
+		for _, q := range inst.Bank().Questions() {
+			columns = append(columns, Column{
+				Name:       fmt.Sprintf("q%d", q.ID),
+				Kind:       KindItem,
+				Dimension:  q.Category,
+				Facet:      q.Facet,
+				QuestionID: q.ID,
+				Reverse:    q.IsReverse,
+			})
+		}
+	}
+	for _, d := range inst.Dimensions {
+		columns = append(columns, Column{Name: d.ID, Kind: KindScore, Dimension: d.ID})
+	}
+	for _, d := range inst.Dimensions {
+		for _, f := range d.Facets {
+			columns = append(columns, Column{Name: d.ID + "_" + f.ID, Kind: KindFacet, Dimension: d.ID, Facet: f.ID})
+		}
+	}
+	return columns
+}
+
+// Writer は診断結果を1行に1件ずつ CSV で書き出します。欠損値は空のセルになります
+type Writer struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+// NewWriter は列 columns の表を書き出す Writer を作成し、ヘッダーを書き込みます
+func NewWriter(w io.Writer, columns []Column) (*Writer, error) {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: cw, columns: columns}, nil
+}
+
+// Write は診断結果を1行書き込みます
+func (w *Writer) Write(a *models.Assessment) error {
+	answers := make(map[int]int, len(a.Responses))
+	for _, r := range a.Responses {
+		answers[r.QuestionID] = r.Score
+	}
+
+	record := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		switch col.Kind {
+		case KindMeta:
+			record[i] = metaValue(a, col.Name)
+		case KindItem:
+			if score, ok := answers[col.QuestionID]; ok {
+				record[i] = strconv.Itoa(score)
+			}
+		case KindScore:
+			if score, ok := a.Result.Get(col.Dimension); ok {
+				record[i] = formatScore(score)
+			}
+		case KindFacet:
+			if score, ok := a.Result.Facets[col.Dimension][col.Facet]; ok {
+				record[i] = formatScore(score)
+			}
+		}
+	}
+	return w.w.Write(record)
+}
+
+// Flush はバッファに残っている行を書き出します
+func (w *Writer) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// metaValue は診断結果の属性の値を返します
+func metaValue(a *models.Assessment, name string) string {
+	demographics := models.Demographics{}
+	if a.Demographics != nil {
+		demographics = *a.Demographics
+	}
+	switch name {
+	case ColumnID:
+		return a.ID
+	case "instrumentId":
+		return a.InstrumentID
+	case "instrumentVersion":
+		return a.InstrumentVersion
+	case "createdAt":
+		return a.CreatedAt.UTC().Format(time.RFC3339)
+	case "normSet":
+		return a.NormSet
+	case ColumnAgeGroup:
+		return demographics.AgeGroup
+	case ColumnGender:
+		return demographics.Gender
+	case "qualityFlags":
+		return strings.Join(a.QualityFlags, ";")
+	}
+	return ""
+}
+
+// formatScore は得点を小数点以下4桁までの文字列にします
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 4, 64)
+}
+
+// WriteCodebook は列ごとの説明（ラベル、次元、下位側面、逆転項目かどうか、取りうる値の範囲）を CSV で書き出します。
+// ラベルは lang の言語で書き出します
+func WriteCodebook(w io.Writer, inst *models.Instrument, columns []Column, lang string) error {
+	localized := inst.Localize(lang)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"variable", "kind", "label", "dimension", "facet", "reverse", "min", "max"})
+
+	for _, col := range columns {
+		var label, reverse, min, max string
+		switch col.Kind {
+		case KindMeta:
+			label = i18n.T(lang, "codebook."+col.Name)
+		case KindItem:
+			q, _ := localized.Bank().Question(col.QuestionID)
+			label = q.Text
+			reverse = "0"
+			if col.Reverse {
+				reverse = "1"
+			}
+		case KindScore:
+			d, _ := localized.Dimension(col.Dimension)
+			label = d.Name
+		case KindFacet:
+			d, _ := localized.Dimension(col.Dimension)
+			for _, f := range d.Facets {
+				if f.ID == col.Facet {
+					label = d.Name + ": " + f.Name
+				}
+			}
+		}
+		if col.Kind != KindMeta {
+			min, max = strconv.Itoa(inst.Scale.Min), strconv.Itoa(inst.Scale.Max)
+		}
+		cw.Write([]string{col.Name, col.Kind, label, col.Dimension, col.Facet, reverse, min, max})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,170 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/csv"
+	"hpcs/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	inst, err := models.ParseInstrument([]byte(`
+id: test
+name: テスト
+scale: {min: 1, max: 5}
+dimensions:
+  - id: neuroticism
+    name: 神経症傾向
+    facets: [{id: anxiety, name: 不安}]
+items:
+  - {id: 1, text: 心配性だ, category: neuroticism, facet: anxiety}
+  - {id: 2, text: 落ち着いている, category: neuroticism, facet: anxiety, isReverse: true}
+translations:
+  en:
+    dimensions: {neuroticism: Neuroticism}
+    facets: {anxiety: Anxiety}
+    items: {1: I worry a lot, 2: I stay calm}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	return inst
+}
+
+func TestWriter(t *testing.T) {
+	inst := exportInstrument(t)
+	assessments := []models.Assessment{
+		{
+			ID:                "a1",
+			InstrumentID:      "test",
+			InstrumentVersion: "1.0.0",
+			Responses:         []models.Response{{QuestionID: 1, Score: 4}, {QuestionID: 2, Score: 2}},
+			Result: models.Result{
+				Neuroticism: models.Float64(4),
+				Facets:      map[string]map[string]float64{"neuroticism": {"anxiety": 4}},
+			},
+			Demographics: &models.Demographics{AgeGroup: "20s"},
+			QualityFlags: []string{"longstring", "low_irv"},
+			CreatedAt:    time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("JST", 9*60*60)),
+		},
+		{ID: "a2", InstrumentID: "test", Responses: []models.Response{{QuestionID: 2, Score: 5}}},
+	}
+
+	tests := []struct {
+		name     string
+		items    bool
+		expected string
+	}{
+		{
+			name:  "得点のみ",
+			items: false,
+			expected: "id,instrumentId,instrumentVersion,createdAt,normSet,ageGroup,gender,qualityFlags,neuroticism,neuroticism_anxiety\n" +
+				"a1,test,1.0.0,2024-05-01T00:30:00Z,,20s,,longstring;low_irv,4.0000,4.0000\n" +
+				"a2,test,,0001-01-01T00:00:00Z,,,,,,\n",
+		},
+		{
+			name:  "質問ごとの回答を含む",
+			items: true,
+			expected: "id,instrumentId,instrumentVersion,createdAt,normSet,ageGroup,gender,qualityFlags,q1,q2,neuroticism,neuroticism_anxiety\n" +
+				"a1,test,1.0.0,2024-05-01T00:30:00Z,,20s,,longstring;low_irv,4,2,4.0000,4.0000\n" +
+				"a2,test,,0001-01-01T00:00:00Z,,,,,,5,,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, Columns(inst, tt.items))
+			if err != nil {
+				t.Fatalf("Failed to create writer: %v", err)
+			}
+			for i := range assessments {
+				if err := w.Write(&assessments[i]); err != nil {
+					t.Fatalf("Failed to write assessment: %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Failed to flush: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Unexpected output:\n%s\nexpected:\n%s", buf.String(), tt.expected)
+			}
+		})
+	}
+
+	// 書き出した wide 形式はそのまま読み込める
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, Columns(inst, true))
+	w.Write(&assessments[0])
+	w.Flush()
+	rows, err := ReadWide(&buf, inst)
+	if err != nil {
+		t.Fatalf("Failed to read exported csv: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Respondent != "a1" || len(rows[0].Responses) != 2 || rows[0].Demographics.AgeGroup != "20s" {
+		t.Errorf("Unexpected round trip: %+v", rows)
+	}
+}
+
+func TestWriteCodebook(t *testing.T) {
+	inst := exportInstrument(t)
+
+	tests := []struct {
+		name     string
+		lang     string
+		expected map[string][]string
+	}{
+		{
+			name: "検査の言語",
+			lang: "ja",
+			expected: map[string][]string{
+				"id":                  {"id", "meta", "診断結果ID", "", "", "", "", ""},
+				"q1":                  {"q1", "item", "心配性だ", "neuroticism", "anxiety", "0", "1", "5"},
+				"q2":                  {"q2", "item", "落ち着いている", "neuroticism", "anxiety", "1", "1", "5"},
+				"neuroticism":         {"neuroticism", "score", "神経症傾向", "neuroticism", "", "", "1", "5"},
+				"neuroticism_anxiety": {"neuroticism_anxiety", "facet", "神経症傾向: 不安", "neuroticism", "anxiety", "", "1", "5"},
+			},
+		},
+		{
+			name: "英語",
+			lang: "en",
+			expected: map[string][]string{
+				"id":                  {"id", "meta", "Result ID", "", "", "", "", ""},
+				"q2":                  {"q2", "item", "I stay calm", "neuroticism", "anxiety", "1", "1", "5"},
+				"neuroticism_anxiety": {"neuroticism_anxiety", "facet", "Neuroticism: Anxiety", "neuroticism", "anxiety", "", "1", "5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCodebook(&buf, inst, Columns(inst, true), tt.lang); err != nil {
+				t.Fatalf("Failed to write codebook: %v", err)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to parse codebook: %v", err)
+			}
+			if strings.Join(records[0], ",") != "variable,kind,label,dimension,facet,reverse,min,max" {
+				t.Errorf("Unexpected header: %v", records[0])
+			}
+			if len(records) != 13 {
+				t.Errorf("Expected 12 variables, got %d", len(records)-1)
+			}
+
+			byName := map[string][]string{}
+			for _, record := range records[1:] {
+				byName[record[0]] = record
+			}
+			for name, expected := range tt.expected {
+				if strings.Join(byName[name], "|") != strings.Join(expected, "|") {
+					t.Errorf("Expected %s to be %v, got %v", name, expected, byName[name])
+				}
+			}
+		})
+	}
+}
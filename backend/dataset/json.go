@@ -0,0 +1,72 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hpcs/models"
+	"io"
+)
+
+// Respondent は JSON で受け渡す1人分の回答です
+type Respondent struct {
+	Respondent   string               `json:"respondent,omitempty"`
+	Responses    []models.Response    `json:"responses"`
+	Demographics *models.Demographics `json:"demographics,omitempty"`
+}
+
+// Batch は JSON で受け渡す複数の回答者の回答です
+type Batch struct {
+	InstrumentID string       `json:"instrumentId,omitempty"`
+	NormSet      string       `json:"normSet,omitempty"`
+	Respondents  []Respondent `json:"respondents"`
+}
+
+// ReadJSON は JSON の回答データを読み込みます。
+// 回答者の配列、instrumentId と respondents を持つオブジェクト、
+// または POST /api/calculate と同じ1人分のオブジェクト（responses を持つ）を受け付けます
+func ReadJSON(r io.Reader) (Batch, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Batch{}, err
+	}
+	data = bytes.TrimSpace(data)
+
+	var batch Batch
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &batch.Respondents); err != nil {
+			return Batch{}, err
+		}
+		return batch, nil
+	}
+
+	var object struct {
+		Batch
+		Respondent
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return Batch{}, err
+	}
+	batch = object.Batch
+	if object.Responses != nil {
+		if batch.Respondents != nil {
+			return Batch{}, fmt.Errorf("specify either responses or respondents, not both")
+		}
+		batch.Respondents = []Respondent{object.Respondent}
+	}
+	return batch, nil
+}
+
+// Rows は回答者ごとの行を返します。行番号は配列の1始まりの位置です
+func (b Batch) Rows() []Row {
+	rows := make([]Row, len(b.Respondents))
+	for i, r := range b.Respondents {
+		rows[i] = Row{
+			Line:         i + 1,
+			Respondent:   r.Respondent,
+			Demographics: r.Demographics,
+			Responses:    r.Responses,
+		}
+	}
+	return rows
+}
@@ -0,0 +1,61 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		instrumentID string
+		respondents  []string
+	}{
+		{
+			name:        "回答者の配列",
+			input:       `[{"respondent":"a","responses":[{"questionId":1,"score":5}]},{"respondent":"b","responses":[]}]`,
+			respondents: []string{"a", "b"},
+		},
+		{
+			name:         "検査IDを含むオブジェクト",
+			input:        `{"instrumentId":"test","respondents":[{"respondent":"a","responses":[{"questionId":1,"score":5}]}]}`,
+			instrumentID: "test",
+			respondents:  []string{"a"},
+		},
+		{
+			name:         "1人分の回答",
+			input:        `{"instrumentId":"test","responses":[{"questionId":1,"score":5}],"demographics":{"gender":"female"}}`,
+			instrumentID: "test",
+			respondents:  []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch, err := ReadJSON(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Failed to read json: %v", err)
+			}
+			if batch.InstrumentID != tt.instrumentID {
+				t.Errorf("Expected instrument %q, got %q", tt.instrumentID, batch.InstrumentID)
+			}
+
+			rows := batch.Rows()
+			if len(rows) != len(tt.respondents) {
+				t.Fatalf("Expected %d rows, got %d", len(tt.respondents), len(rows))
+			}
+			for i, row := range rows {
+				if row.Line != i+1 || row.Respondent != tt.respondents[i] {
+					t.Errorf("Unexpected row %d: %+v", i, row)
+				}
+			}
+		})
+	}
+
+	for _, input := range []string{`[{"respondent":`, `{"responses":[],"respondents":[]}`} {
+		if _, err := ReadJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package dataset は1行に1人の回答者を並べた表形式（列 q1..qN）の回答データを読み書きします
+package dataset
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hpcs/models"
+	"hpcs/validation"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 質問以外に読み取る列の名前
+const (
+	ColumnRespondent = "respondent"
+	// ColumnID は書き出した診断結果のIDの列です。読み込むときは respondent と同じに扱います
+	ColumnID       = "id"
+	ColumnAgeGroup = "ageGroup"
+	ColumnGender   = "gender"
+)
+
+// Row は1人の回答者の回答です
+type Row struct {
+	// Line はヘッダーを除いた1始まりの行番号です
+	Line int
+	// Respondent は回答者の識別子です（respondent 列がない場合は空）
+	Respondent   string
+	Demographics *models.Demographics
+	Responses    []models.Response
+	// Errors は値を読み取れなかったセルの問題です
+	Errors validation.Errors
+
+	// columns は CSV から読み込んだ行であることを表します
+	columns bool
+}
+
+// Validate は値を読み取れなかったセルの問題と回答のバリデーションの問題をまとめて返します。
+// CSV から読み込んだ行では問題の位置を列名（q1 など）で表します
+func (r Row) Validate(inst *models.Instrument) validation.Errors {
+	errs := append(validation.Errors{}, r.Errors...)
+	for _, fe := range validation.Responses(inst, r.Responses) {
+		if r.columns {
+			fe.Field = fmt.Sprintf("q%d", fe.QuestionID)
+		}
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// column は表の列の意味です
+type column struct {
+	questionID int
+	name       string
+}
+
+// ReadWide は CSV の回答データを読み込みます。
+// 列 q1..qN の空のセルは未回答として扱い、respondent, ageGroup, gender 以外の列は無視します。
+// ヘッダーに問題がある場合はエラーを返し、セルの問題は各行の Errors に記録します
+func ReadWide(r io.Reader, inst *models.Instrument) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("csv has no header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns, err := parseHeader(header, inst)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv row %d: %w", line, err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		rows = append(rows, parseRecord(line, record, columns))
+	}
+	return rows, nil
+}
+
+// parseHeader はヘッダーの各列の意味を判定します
+func parseHeader(header []string, inst *models.Instrument) ([]column, error) {
+	columns := make([]column, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if i == 0 {
+			// Excel が付ける BOM を取り除く
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+
+		switch strings.ToLower(name) {
+		case strings.ToLower(ColumnRespondent), ColumnID:
+			columns[i] = column{name: ColumnRespondent}
+		case strings.ToLower(ColumnAgeGroup), "age_group":
+			columns[i] = column{name: ColumnAgeGroup}
+		case ColumnGender:
+			columns[i] = column{name: ColumnGender}
+		default:
+			id, ok := questionColumn(name)
+			if !ok {
+				continue
+			}
+			if _, exists := inst.Bank().Question(id); !exists {
+				return nil, fmt.Errorf("csv column %s refers to unknown question: %d", name, id)
+			}
+			columns[i] = column{questionID: id, name: fmt.Sprintf("q%d", id)}
+		}
+
+		if seen[columns[i].name] {
+			return nil, fmt.Errorf("duplicate csv column: %s", name)
+		}
+		seen[columns[i].name] = true
+	}
+	return columns, nil
+}
+
+// questionColumn は q12 のような列名から質問IDを取り出します
+func questionColumn(name string) (int, bool) {
+	if len(name) < 2 || (name[0] != 'q' && name[0] != 'Q') {
+		return 0, false
+	}
+	id, err := strconv.Atoi(name[1:])
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// parseRecord は1行を回答に変換します
+func parseRecord(line int, record []string, columns []column) Row {
+	row := Row{Line: line, Responses: []models.Response{}, columns: true}
+	var demographics models.Demographics
+
+	for i, value := range record {
+		if i >= len(columns) {
+			break
+		}
+		value = strings.TrimSpace(value)
+		col := columns[i]
+		switch {
+		case col.name == ColumnRespondent:
+			row.Respondent = value
+		case col.name == ColumnAgeGroup:
+			demographics.AgeGroup = value
+		case col.name == ColumnGender:
+			demographics.Gender = value
+		case col.questionID > 0 && value != "":
+			score, err := strconv.Atoi(value)
+			if err != nil {
+				row.Errors = append(row.Errors, validation.InvalidValue(col.name, col.questionID, value))
+				continue
+			}
+			row.Responses = append(row.Responses, models.Response{QuestionID: col.questionID, Score: score})
+		}
+	}
+
+	if demographics != (models.Demographics{}) {
+		row.Demographics = &demographics
+	}
+	return row
+}
+
+// isBlank はすべてのセルが空の行かどうかを返します
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
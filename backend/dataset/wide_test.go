@@ -0,0 +1,107 @@
+package dataset
+
+import (
+	"hpcs/models"
+	"hpcs/validation"
+	"strings"
+	"testing"
+)
+
+func testInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	inst, err := models.ParseInstrument([]byte(`
+id: test
+scale: {min: 1, max: 5}
+dimensions: [{id: neuroticism}]
+items:
+  - {id: 1, category: neuroticism}
+  - {id: 2, category: neuroticism}
+  - {id: 3, category: neuroticism}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	return inst
+}
+
+func TestReadWide(t *testing.T) {
+	input := "\ufeffid,name,Q1,q2,q3,ageGroup,gender\n" +
+		"emp-1,Sato,5,4,3,20s,female\n" +
+		"emp-2,Suzuki,2,,x,,\n" +
+		",,,,,,\n" +
+		"emp-3,Tanaka,1,1\n"
+
+	rows, err := ReadWide(strings.NewReader(input), testInstrument(t))
+	if err != nil {
+		t.Fatalf("Failed to read csv: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.Line != 1 || first.Respondent != "emp-1" || len(first.Responses) != 3 || len(first.Errors) != 0 {
+		t.Errorf("Unexpected first row: %+v", first)
+	}
+	if first.Demographics == nil || first.Demographics.AgeGroup != "20s" || first.Demographics.Gender != "female" {
+		t.Errorf("Unexpected demographics: %+v", first.Demographics)
+	}
+
+	// 空のセルは未回答、整数でない値はエラー
+	second := rows[1]
+	if len(second.Responses) != 1 || second.Responses[0] != (models.Response{QuestionID: 1, Score: 2}) {
+		t.Errorf("Unexpected responses: %+v", second.Responses)
+	}
+	if len(second.Errors) != 1 || second.Errors[0].Field != "q3" || second.Errors[0].Code != validation.CodeInvalidValue {
+		t.Errorf("Unexpected errors: %+v", second.Errors)
+	}
+	if second.Demographics != nil {
+		t.Errorf("Expected no demographics, got %+v", second.Demographics)
+	}
+	if errs := first.Validate(testInstrument(t)); len(errs) != 0 {
+		t.Errorf("Expected first row to be valid, got %v", errs)
+	}
+
+	// 空行は読み飛ばし、行番号は元の位置のまま
+	if rows[2].Line != 4 || len(rows[2].Responses) != 2 {
+		t.Errorf("Unexpected third row: %+v", rows[2])
+	}
+}
+
+func TestRowValidate(t *testing.T) {
+	rows, err := ReadWide(strings.NewReader("q1,q2,q3\n6,x,3\n"), testInstrument(t))
+	if err != nil {
+		t.Fatalf("Failed to read csv: %v", err)
+	}
+
+	// 読み取りの問題とバリデーションの問題をまとめ、位置は列名で表す
+	errs := rows[0].Validate(testInstrument(t))
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	if errs[0].Field != "q2" || errs[0].Code != validation.CodeInvalidValue {
+		t.Errorf("Unexpected first error: %+v", errs[0])
+	}
+	if errs[1].Field != "q1" || errs[1].Code != validation.CodeOutOfRange {
+		t.Errorf("Unexpected second error: %+v", errs[1])
+	}
+}
+
+func TestReadWideHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "空のファイル", input: ""},
+		{name: "存在しない質問", input: "q1,q9\n1,2\n"},
+		{name: "重複した列", input: "q1,Q1\n1,2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadWide(strings.NewReader(tt.input), testInstrument(t)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
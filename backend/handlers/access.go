@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/auth"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// superadmins はシステム全体の管理者のユーザーIDです
+var superadmins map[string]bool
+
+// SetSuperadmins はシステム全体の管理者とするユーザーのIDを設定します。
+// 登録時にメールアドレスの所有を確認していないため、メールアドレスではなく発行済みのユーザーIDで指定します
+func SetSuperadmins(userIDs []string) {
+	superadmins = make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			superadmins[id] = true
+		}
+	}
+}
+
+// コンテキストに権限と認可済みの診断結果を保存するキー
+const (
+	principalKey  = "principal"
+	assessmentKey = "assessment"
+)
+
+// errForbidden は権限のない操作を行おうとした場合のエラーです
+var errForbidden = i18n.New("error.forbidden")
+
+// abortForbidden は権限がない場合の 403 を返します
+func abortForbidden(c *gin.Context) {
+	abortWithError(c, http.StatusForbidden, CodeForbidden, errForbidden)
+}
+
+// currentPrincipal は認証済みのユーザーの権限を返します。匿名の場合はゼロ値を返します。
+// 所属は本人が承諾したもののみを、リクエストごとに1度だけ読み込みます
+func currentPrincipal(c *gin.Context) (auth.Principal, error) {
+	if p, ok := c.Get(principalKey); ok {
+		return p.(auth.Principal), nil
+	}
+	user := currentUser(c)
+	if user == nil {
+		return auth.Principal{}, nil
+	}
+
+	memberships, err := store.ListMemberships(storage.MembershipFilter{UserID: user.ID, Accepted: true})
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	p := auth.Principal{
+		UserID:      user.ID,
+		Superadmin:  superadmins[user.ID],
+		Memberships: memberships,
+	}
+	c.Set(principalKey, p)
+	return p, nil
+}
+
+// canReadResult は現在のユーザーが診断結果を参照できるかどうかを返します。
+// 所有者のいない診断結果は誰でも、所有者のいる診断結果は所有者、所有者のチームのマネージャー、
+// 所有者の組織の管理者とシステム全体の管理者が参照できます（所有者が承諾した所属のみ）。招待から回答された診断結果は実施の管理者も参照でき、
+// 招待から匿名で回答された診断結果は所有者のいない診断結果として公開しません
+func canReadResult(c *gin.Context, a *models.Assessment) (bool, error) {
+	if (a.OwnerID != "" || a.CampaignID == "") && canAccess(c, a.OwnerID) {
+		return true, nil
+	}
+	p, err := currentPrincipal(c)
+	if err != nil || p.UserID == "" {
+		return false, err
+	}
+	if p.Superadmin {
+		return true, nil
+	}
+	// 実施の管理者は、招待から回答された診断結果を参照できる
+	if a.CampaignID != "" {
+		campaign, err := store.GetCampaign(a.CampaignID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return false, err
+		}
+		if campaign != nil && p.CanManageCampaign(*campaign) {
+			return true, nil
+		}
+	}
+	if a.OwnerID == "" {
+		return false, nil
+	}
+	memberships, err := store.ListMemberships(storage.MembershipFilter{UserID: a.OwnerID, Accepted: true})
+	if err != nil {
+		return false, err
+	}
+	return p.CanView(memberships), nil
+}
+
+// AuthorizeResult はパスの ID の診断結果を現在のユーザーが参照できるかを確認するミドルウェアです。
+// 参照できない場合は、診断結果の存在がわからないよう見つからない場合と同じ 404 を返します
+func AuthorizeResult() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if store == nil {
+			abortWithStorageDisabled(c)
+			return
+		}
+		assessment, ok := loadAssessmentByID(c, c.Param("id"))
+		if !ok {
+			return
+		}
+		c.Set(assessmentKey, assessment)
+		c.Next()
+	}
+}
+
+// resultScope は一覧や書き出しで参照する診断結果を絞り込む条件を設定します。
+// campaignId を指定した場合は実施で回答された診断結果、teamId を指定した場合はチームのメンバー、
+// organizationId を指定した場合は組織のメンバー（いずれも所属を承諾したメンバーのみ）の診断結果になり、
+// いずれも指定しない場合は自分の診断結果（匿名の場合は所有者のいない診断結果）になります。
+// 権限がない場合などはエラーを記録して false を返します
+func resultScope(c *gin.Context, filter storage.AssessmentFilter) (storage.AssessmentFilter, bool) {
+	campaignID, teamID, organizationID := c.Query("campaignId"), c.Query("teamId"), c.Query("organizationId")
+	if campaignID == "" && teamID == "" && organizationID == "" {
+		return ownerFilter(c, filter), true
+	}
+	if currentUser(c) == nil {
+		abortUnauthorized(c, CodeUnauthorized, i18n.New("error.unauthorized"))
+		return filter, false
+	}
+	if campaignID != "" {
+		campaign, ok := authorizeCampaign(c, campaignID)
+		if !ok {
+			return filter, false
+		}
+		filter.CampaignID = campaign.ID
+		return filter, true
+	}
+	p, err := currentPrincipal(c)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return filter, false
+	}
+
+	membershipFilter := storage.MembershipFilter{Accepted: true}
+	if teamID != "" {
+		team, ok := loadTeam(c, teamID)
+		if !ok {
+			return filter, false
+		}
+		if !p.CanManageTeam(*team) {
+			abortForbidden(c)
+			return filter, false
+		}
+		membershipFilter.TeamID = team.ID
+	} else {
+		if _, ok := loadOrganization(c, organizationID); !ok {
+			return filter, false
+		}
+		if !p.IsOrgAdmin(organizationID) {
+			abortForbidden(c)
+			return filter, false
+		}
+		membershipFilter.OrganizationID = organizationID
+	}
+
+	memberships, err := store.ListMemberships(membershipFilter)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return filter, false
+	}
+	filter.OwnerIDs = memberUserIDs(memberships)
+	return filter, true
+}
+
+// ownerFilter は現在のユーザーが参照できる診断結果に絞り込む条件を設定します。
+// 認証済みの場合は自分の診断結果、匿名の場合は所有者のいない診断結果（招待から回答されたものを除く）のみになります
+func ownerFilter(c *gin.Context, filter storage.AssessmentFilter) storage.AssessmentFilter {
+	if id := currentUserID(c); id != "" {
+		filter.OwnerID = id
+	} else {
+		filter.Unowned = true
+	}
+	return filter
+}
+
+// memberUserIDs は所属しているユーザーのIDを重複なく返します
+func memberUserIDs(memberships []models.Membership) []string {
+	ids := []string{}
+	seen := make(map[string]bool, len(memberships))
+	for _, m := range memberships {
+		if !seen[m.UserID] {
+			seen[m.UserID] = true
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+// loadOrganization は組織を読み込みます。見つからない場合はエラーを記録して false を返します
+func loadOrganization(c *gin.Context, id string) (*models.Organization, bool) {
+	org, err := store.GetOrganization(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.organization_not_found", id))
+		return nil, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	return org, true
+}
+
+// loadTeam はチームを読み込みます。見つからない場合はエラーを記録して false を返します
+func loadTeam(c *gin.Context, id string) (*models.Team, bool) {
+	team, err := store.GetTeam(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.team_not_found", id))
+		return nil, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	return team, true
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"hpcs/analytics"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"hpcs/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minGroupSize は集団の集計を公開する最小の人数です
+var minGroupSize = analytics.DefaultMinGroupSize
+
+// SetMinGroupSize は集団の集計を公開する最小の人数を設定します。1未満の場合は既定値を使います
+func SetMinGroupSize(n int) {
+	if n < 1 {
+		n = analytics.DefaultMinGroupSize
+	}
+	minGroupSize = n
+}
+
+// GetReliability は保存済みの診断結果から信頼性の統計量を返すハンドラーです。
+// 集計する診断結果は一覧と同じく、自分の診断結果または teamId などで指定した参照できる範囲に限ります
+func GetReliability(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	inst, err := lookupInstrument(c.Query("instrumentId"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+
+	filter, ok := resultScope(c, storage.AssessmentFilter{InstrumentID: inst.ID})
+	if !ok {
+		return
+	}
+	assessments, err := store.ListAssessments(filter)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	responseSets := make([][]models.Response, len(assessments))
+	for i, a := range assessments {
+		responseSets[i] = a.Responses
+	}
+
+	c.JSON(http.StatusOK, analytics.Reliability(inst, responseSets))
+}
+
+// PostReliability はアップロードされた回答から信頼性の統計量を返すハンドラーです
+func PostReliability(c *gin.Context) {
+	var request struct {
+		InstrumentID string `json:"instrumentId"`
+		Respondents  []struct {
+			Responses []models.Response `json:"responses"`
+		} `json:"respondents"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+
+	inst, err := lookupInstrument(request.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+
+	// すべての回答者の問題をまとめて返す
+	var errs validation.Errors
+	responseSets := make([][]models.Response, len(request.Respondents))
+	for i, respondent := range request.Respondents {
+		errs = append(errs, validation.Responses(inst, respondent.Responses).ForRespondent(i)...)
+		responseSets[i] = respondent.Responses
+	}
+	if len(errs) > 0 {
+		respondValidationError(c, errs)
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics.Reliability(inst, responseSets))
+}
+
+// teamProfile はチームの構成の集計です
+type teamProfile struct {
+	TeamID string `json:"teamId"`
+	analytics.GroupProfile
+}
+
+// GetTeamProfile はチームのメンバーの最新の診断結果から次元ごとの得点分布を返すハンドラーです
+// （チームのマネージャーと組織の管理者のみ）。回答者が最小人数に満たない場合は統計量を省略します
+func GetTeamProfile(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+	team, ok := authorizeTeam(c, true)
+	if !ok {
+		return
+	}
+
+	inst, err := lookupInstrument(c.Query("instrumentId"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+
+	memberships, err := store.ListMemberships(storage.MembershipFilter{TeamID: team.ID, Accepted: true})
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	assessments, err := store.ListAssessments(storage.AssessmentFilter{
+		InstrumentID: inst.ID,
+		OwnerIDs:     memberUserIDs(memberships),
+	})
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	// 診断結果は新しい順なので、メンバーごとに最初の結果を使う
+	var results []models.Result
+	seen := make(map[string]bool)
+	for _, a := range assessments {
+		if !seen[a.OwnerID] {
+			seen[a.OwnerID] = true
+			results = append(results, a.Result)
+		}
+	}
+
+	c.JSON(http.StatusOK, teamProfile{
+		TeamID:       team.ID,
+		GroupProfile: analytics.Profile(inst, results, minGroupSize),
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpcs/analytics"
+	"hpcs/models"
+	"hpcs/scoring"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupAnalyticsRouter() *gin.Engine {
+	r := setupRouter()
+	r.POST("/api/analytics/reliability", PostReliability)
+	return r
+}
+
+// respondentsJSON は神経症傾向の4項目に回答した回答者のJSONを生成します
+func respondentsJSON(rows [][]int) string {
+	var respondents []string
+	for _, row := range rows {
+		respondents = append(respondents, fmt.Sprintf(
+			`{"responses":[{"questionId":1,"score":%d},{"questionId":2,"score":%d},{"questionId":3,"score":%d},{"questionId":4,"score":%d}]}`,
+			row[0], row[1], row[2], row[3]))
+	}
+	return "[" + strings.Join(respondents, ",") + "]"
+}
+
+func TestPostReliability(t *testing.T) {
+	router := setupAnalyticsRouter()
+
+	rows := [][]int{{4, 5, 4, 3}, {2, 2, 3, 2}, {5, 4, 5, 5}, {3, 3, 2, 3}, {1, 2, 1, 2}}
+	w := doJSON(router, "POST", "/api/analytics/reliability", `{"respondents":`+respondentsJSON(rows)+`}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var report analytics.ReliabilityReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal report: %v", err)
+	}
+	if report.InstrumentID != "hpcs-74" || report.Respondents != 5 || len(report.Dimensions) != 5 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	// 神経症傾向は4項目に回答していない回答者しかいないため計算されない
+	neuroticism := report.Dimensions[0]
+	if neuroticism.Dimension != "neuroticism" || neuroticism.N != 0 || neuroticism.Alpha != nil {
+		t.Errorf("Expected no complete cases for neuroticism, got %+v", neuroticism)
+	}
+
+	w = doJSON(router, "POST", "/api/analytics/reliability", `{"respondents":[{"responses":[{"questionId":1,"score":9}]}]}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "respondent 0") {
+		t.Errorf("Expected validation error for respondent 0, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetReliability(t *testing.T) {
+	setupStore(t)
+	setupAuth(t)
+	router := setupAuthRouter()
+	router.GET("/api/analytics/reliability", RequireAuth(), GetReliability)
+
+	// 認証が必要
+	expectStatus(t, router, "GET", "/api/analytics/reliability", "", "", http.StatusUnauthorized)
+
+	user := registerUser(t, router, "user@example.com")
+	other := registerUser(t, router, "other@example.com")
+	inst := instruments.Default()
+	save := func(ownerID string, score int) {
+		var responses []models.Response
+		for _, q := range inst.Bank().Questions() {
+			// 逆転項目は反対側に回答し、反転後の得点が揃うようにする
+			v := score
+			if q.IsReverse {
+				v = 6 - score
+			}
+			responses = append(responses, models.Response{QuestionID: q.ID, Score: v})
+		}
+		scoreAndStore(scoring.New(inst), scoring.Submission{Responses: responses}, resultOrigin{OwnerID: ownerID})
+	}
+	for _, score := range []int{1, 3, 5, 4} {
+		save(user.User.ID, score)
+	}
+	// 他のユーザーと匿名の診断結果は集計しない
+	save(other.User.ID, 2)
+	save("", 2)
+
+	var report analytics.ReliabilityReport
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/analytics/reliability?instrumentId=hpcs-74", "", user.AccessToken, http.StatusOK), &report)
+	if report.Respondents != 4 {
+		t.Errorf("Expected 4 respondents, got %d", report.Respondents)
+	}
+	for _, d := range report.Dimensions {
+		if d.Alpha == nil || *d.Alpha != 1 {
+			t.Errorf("Expected perfectly consistent %s to have alpha 1, got %v", d.Dimension, d.Alpha)
+		}
+	}
+
+	expectStatus(t, router, "GET", "/api/analytics/reliability?instrumentId=unknown", "", user.AccessToken, http.StatusBadRequest)
+	expectStatus(t, router, "GET", "/api/analytics/reliability?teamId=missing", "", user.AccessToken, http.StatusNotFound)
+}
+
+func TestGetTeamProfile(t *testing.T) {
+	router := setupOrganizationsRouter(t)
+	router.GET("/api/teams/:id/profile", RequireAuth(), GetTeamProfile)
+	SetMinGroupSize(3)
+	t.Cleanup(func() { SetMinGroupSize(0) })
+
+	root := registerSuperadmin(t, router, "root@example.com")
+	manager := registerUser(t, router, "manager@example.com").AccessToken
+	var org models.Organization
+	var team models.Team
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/organizations", `{"name":"Acme"}`, root, http.StatusCreated), &org)
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/organizations/"+org.ID+"/teams", `{"name":"Sales"}`, root, http.StatusCreated), &team)
+	addMember(t, router, "/api/organizations/"+org.ID, `{"email":"manager@example.com"}`, root, manager)
+	addMember(t, router, "/api/teams/"+team.ID, `{"email":"manager@example.com","role":"manager"}`, root, manager)
+
+	// 神経症傾向の4項目に同じ点数で回答する
+	calculate := func(token string, score int) float64 {
+		t.Helper()
+		body := fmt.Sprintf(`{"responses":[{"questionId":1,"score":%[1]d},{"questionId":2,"score":%[1]d},{"questionId":3,"score":%[1]d},{"questionId":4,"score":%[1]d}]}`, score)
+		var result calculateResponse
+		json.Unmarshal(expectStatus(t, router, "POST", "/api/calculate", body, token, http.StatusOK), &result)
+		return *result.Neuroticism
+	}
+
+	path := "/api/teams/" + team.ID + "/profile"
+	var members []string
+	var expected float64
+	for i, score := range []int{2, 3, 4} {
+		member := registerUser(t, router, fmt.Sprintf("member%d@example.com", i)).AccessToken
+		addMember(t, router, "/api/organizations/"+org.ID, fmt.Sprintf(`{"email":"member%d@example.com"}`, i), root, member)
+		addMember(t, router, "/api/teams/"+team.ID, fmt.Sprintf(`{"email":"member%d@example.com"}`, i), manager, member)
+		members = append(members, member)
+		calculate(member, 1)
+		expected += calculate(member, score) / 3
+	}
+	// チーム外の回答者の結果は含めない
+	calculate(registerUser(t, router, "outsider@example.com").AccessToken, 5)
+
+	var profile teamProfile
+	json.Unmarshal(expectStatus(t, router, "GET", path, "", manager, http.StatusOK), &profile)
+	if profile.TeamID != team.ID || profile.Respondents != 3 || profile.Suppressed || profile.MinGroupSize != 3 {
+		t.Fatalf("Unexpected profile: %+v", profile)
+	}
+	var neuroticism analytics.DimensionProfile
+	for _, d := range profile.Dimensions {
+		if d.Dimension == models.Neuroticism {
+			neuroticism = d
+		}
+	}
+	// メンバーごとに最新の結果のみを使う
+	if neuroticism.N != 3 || neuroticism.Mean == nil || !almostEqual(*neuroticism.Mean, expected, 0.001) || len(neuroticism.Histogram) == 0 {
+		t.Errorf("Expected mean %v of the latest results, got %+v", expected, neuroticism)
+	}
+
+	expectStatus(t, router, "GET", path, "", members[0], http.StatusForbidden)
+	expectStatus(t, router, "GET", path, "", "", http.StatusUnauthorized)
+	expectStatus(t, router, "GET", "/api/teams/missing/profile", "", manager, http.StatusNotFound)
+	expectStatus(t, router, "GET", path+"?instrumentId=unknown", "", manager, http.StatusBadRequest)
+
+	// 最小人数に満たない場合は統計量を返さない
+	SetMinGroupSize(4)
+	var suppressed teamProfile
+	json.Unmarshal(expectStatus(t, router, "GET", path, "", root, http.StatusOK), &suppressed)
+	if !suppressed.Suppressed {
+		t.Errorf("Expected the profile to be suppressed: %+v", suppressed)
+	}
+	for _, d := range suppressed.Dimensions {
+		if !d.Suppressed || d.Mean != nil {
+			t.Errorf("Expected dimension %s to be suppressed: %+v", d.Dimension, d)
+		}
+	}
+}
@@ -3,10 +3,14 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"hpcs/models"
+	"hpcs/quality"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +18,7 @@ import (
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(RequestID(), Language(), ErrorHandler())
 	r.POST("/api/calculate", CalculateScore)
 	return r
 }
@@ -21,15 +26,10 @@ func setupRouter() *gin.Engine {
 func TestCalculateScoreAPI(t *testing.T) {
 	router := setupRouter()
 
-	// テストケース1: 正常なリクエスト
-	responses := []models.Response{
-		{QuestionID: 1, Score: 5},
-		{QuestionID: 2, Score: 4},
-		{QuestionID: 29, Score: 1}, // 逆転項目
-		{QuestionID: 5, Score: 4},
-		{QuestionID: 9, Score: 5},
-		{QuestionID: 13, Score: 4},
-		{QuestionID: 17, Score: 5},
+	// テストケース1: 正常なリクエスト（すべての質問に回答）
+	var responses []models.Response
+	for id := 1; id <= 74; id++ {
+		responses = append(responses, models.Response{QuestionID: id, Score: id%5 + 1})
 	}
 
 	requestBody := struct {
@@ -59,20 +59,20 @@ func TestCalculateScoreAPI(t *testing.T) {
 	}
 
 	// 結果の検証
-	if result.Neuroticism <= 0 || result.Neuroticism > 5 {
-		t.Errorf("Invalid neuroticism score: %f", result.Neuroticism)
+	if result.Neuroticism == nil || *result.Neuroticism <= 0 || *result.Neuroticism > 5 {
+		t.Errorf("Invalid neuroticism score: %v", result.Neuroticism)
 	}
-	if result.Extraversion <= 0 || result.Extraversion > 5 {
-		t.Errorf("Invalid extraversion score: %f", result.Extraversion)
+	if result.Extraversion == nil || *result.Extraversion <= 0 || *result.Extraversion > 5 {
+		t.Errorf("Invalid extraversion score: %v", result.Extraversion)
 	}
-	if result.Conscientiousness <= 0 || result.Conscientiousness > 5 {
-		t.Errorf("Invalid conscientiousness score: %f", result.Conscientiousness)
+	if result.Conscientiousness == nil || *result.Conscientiousness <= 0 || *result.Conscientiousness > 5 {
+		t.Errorf("Invalid conscientiousness score: %v", result.Conscientiousness)
 	}
-	if result.Agreeableness <= 0 || result.Agreeableness > 5 {
-		t.Errorf("Invalid agreeableness score: %f", result.Agreeableness)
+	if result.Agreeableness == nil || *result.Agreeableness <= 0 || *result.Agreeableness > 5 {
+		t.Errorf("Invalid agreeableness score: %v", result.Agreeableness)
 	}
-	if result.Openness <= 0 || result.Openness > 5 {
-		t.Errorf("Invalid openness score: %f", result.Openness)
+	if result.Openness == nil || *result.Openness <= 0 || *result.Openness > 5 {
+		t.Errorf("Invalid openness score: %v", result.Openness)
 	}
 
 	// テストケース2: 不正なリクエスト（空の回答）
@@ -95,3 +95,88 @@ func TestCalculateScoreAPI(t *testing.T) {
 		t.Errorf("Expected status code %d for invalid JSON, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+func TestCalculateScoreQuality(t *testing.T) {
+	router := setupRouter()
+
+	// 74問すべてに「5」と回答
+	var responses []string
+	for i := 1; i <= 74; i++ {
+		responses = append(responses, fmt.Sprintf(`{"questionId":%d,"score":5}`, i))
+	}
+	startedAt := time.Now().Add(-30 * time.Second).Format(time.RFC3339)
+	body := `{"startedAt":"` + startedAt + `","responses":[` + strings.Join(responses, ",") + `]}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Quality quality.Report `json:"quality"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if !response.Quality.Flagged || response.Quality.LongestRun != 74 {
+		t.Errorf("Expected straight-lined submission to be flagged, got %+v", response.Quality)
+	}
+	expected := []string{quality.FlagLongstring, quality.FlagLowVariability, quality.FlagTooFast}
+	if strings.Join(response.Quality.Flags, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected flags %v, got %v", expected, response.Quality.Flags)
+	}
+	// 逆転項目を含む組（1 と 32）では同じ回答が矛盾として現れる（8組中1組で差が4）
+	if response.Quality.Inconsistency == nil || *response.Quality.Inconsistency != 0.5 {
+		t.Errorf("Expected inconsistency 0.5, got %v", response.Quality.Inconsistency)
+	}
+}
+
+func TestCalculateScoreInsufficientDimensions(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	// 神経症傾向は8項目中4項目、外向性は10項目中1項目に回答する
+	req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(
+		`{"responses":[{"questionId":1,"score":4},{"questionId":2,"score":4},{"questionId":3,"score":4},{"questionId":4,"score":4},{"questionId":5,"score":2}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	// 項目の半数に満たない次元と回答のない次元は null で返される
+	if string(response["openness"]) != "null" || string(response["extraversion"]) != "null" {
+		t.Errorf("Expected openness and extraversion to be null, got %s and %s", response["openness"], response["extraversion"])
+	}
+	if string(response["neuroticism"]) != "4" {
+		t.Errorf("Expected neuroticism to be 4, got %s", response["neuroticism"])
+	}
+
+	var coverage map[string]models.DimensionCoverage
+	json.Unmarshal(response["coverage"], &coverage)
+	if got := coverage["neuroticism"]; got.Answered != 4 || got.Expected != 8 || !got.Sufficient {
+		t.Errorf("Unexpected neuroticism coverage: %+v", got)
+	}
+	if got := coverage["extraversion"]; got.Answered != 1 || got.Expected != 10 || got.Sufficient {
+		t.Errorf("Unexpected extraversion coverage: %+v", got)
+	}
+	if got := coverage["openness"]; got.Answered != 0 || got.Expected != 19 || got.Sufficient {
+		t.Errorf("Unexpected openness coverage: %+v", got)
+	}
+}
+
+// 浮動小数点数の比較用ヘルパー関数
+func almostEqual(a, b, tolerance float64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/auth"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokens はトークンの発行と検証を行います（nil の場合は認証を行いません）
+var tokens *auth.Issuer
+
+// SetAuth はハンドラーが使用するトークンの発行者を設定します
+func SetAuth(issuer *auth.Issuer) {
+	tokens = issuer
+}
+
+// userKey はコンテキストに認証済みのユーザーを保存するキーです
+const userKey = "user"
+
+// errInvalidToken はトークンが不正または期限切れの場合のエラーです
+var errInvalidToken = i18n.New("error.invalid_token")
+
+// authResponse は登録とログインのレスポンスです
+type authResponse struct {
+	User *models.User `json:"user"`
+	auth.TokenPair
+}
+
+// credentials は登録とログインのリクエストです
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+// bindCredentials はリクエストのボディを読み込みます。失敗した場合はエラーを記録して false を返します
+func bindCredentials(c *gin.Context) (credentials, bool) {
+	if tokens == nil {
+		abortWithError(c, http.StatusServiceUnavailable, CodeAuthDisabled, i18n.New("error.auth_disabled"))
+		return credentials{}, false
+	}
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return credentials{}, false
+	}
+	var request credentials
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return credentials{}, false
+	}
+	request.Email = strings.TrimSpace(request.Email)
+	return request, true
+}
+
+// respondTokens はユーザーのトークンを発行して返します
+func respondTokens(c *gin.Context, status int, user *models.User) {
+	pair, err := tokens.Issue(user.ID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(status, authResponse{User: user, TokenPair: pair})
+}
+
+// Register は新しいユーザーを登録し、トークンを発行するハンドラーです
+func Register(c *gin.Context) {
+	request, ok := bindCredentials(c)
+	if !ok {
+		return
+	}
+
+	if addr, err := mail.ParseAddress(request.Email); err != nil || addr.Address != request.Email {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_email", request.Email))
+		return
+	}
+	if len([]rune(request.Password)) < auth.MinPasswordLength {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.password_too_short", auth.MinPasswordLength))
+		return
+	}
+	if len(request.Password) > auth.MaxPasswordBytes {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.password_too_long", auth.MaxPasswordBytes))
+		return
+	}
+
+	hash, err := auth.HashPassword(request.Password)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	user := &models.User{
+		Email:        request.Email,
+		Name:         strings.TrimSpace(request.Name),
+		PasswordHash: hash,
+	}
+	if err := store.CreateUser(user); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			abortWithError(c, http.StatusConflict, CodeEmailTaken, i18n.New("error.email_taken", request.Email))
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	respondTokens(c, http.StatusCreated, user)
+}
+
+// Login はメールアドレスとパスワードを確認し、トークンを発行するハンドラーです
+func Login(c *gin.Context) {
+	request, ok := bindCredentials(c)
+	if !ok {
+		return
+	}
+
+	// メールアドレスの登録の有無がわからないよう、どちらの場合も同じエラーを返す
+	user, err := store.GetUserByEmail(request.Email)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	if user == nil || !auth.CheckPassword(user.PasswordHash, request.Password) {
+		abortUnauthorized(c, CodeInvalidCredentials, i18n.New("error.invalid_credentials"))
+		return
+	}
+
+	respondTokens(c, http.StatusOK, user)
+}
+
+// Refresh はリフレッシュトークンから新しいトークンを発行するハンドラーです
+func Refresh(c *gin.Context) {
+	if tokens == nil {
+		abortWithError(c, http.StatusServiceUnavailable, CodeAuthDisabled, i18n.New("error.auth_disabled"))
+		return
+	}
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	var request struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+
+	user, ok := verifyToken(c, request.RefreshToken, auth.TokenRefresh)
+	if !ok {
+		return
+	}
+
+	respondTokens(c, http.StatusOK, user)
+}
+
+// currentUserResponse は認証済みのユーザーとその権限です
+type currentUserResponse struct {
+	*models.User
+	Superadmin  bool                `json:"superadmin,omitempty"`
+	Memberships []models.Membership `json:"memberships"`
+}
+
+// GetCurrentUser は認証済みのユーザーと所属を返すハンドラーです。所属には承諾していないものも含みます
+func GetCurrentUser(c *gin.Context) {
+	p, err := currentPrincipal(c)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	memberships, err := store.ListMemberships(storage.MembershipFilter{UserID: p.UserID})
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusOK, currentUserResponse{
+		User:        currentUser(c),
+		Superadmin:  p.Superadmin,
+		Memberships: memberships,
+	})
+}
+
+// Authenticate は Authorization ヘッダーの Bearer トークンを検証し、ユーザーをコンテキストに保存するミドルウェアです。
+// ヘッダーがない場合は匿名のまま続行し、トークンが不正な場合は 401 を返します
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" || tokens == nil || store == nil {
+			c.Next()
+			return
+		}
+
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			abortUnauthorized(c, CodeInvalidToken, errInvalidToken)
+			return
+		}
+		user, ok := verifyToken(c, strings.TrimSpace(token), auth.TokenAccess)
+		if !ok {
+			return
+		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// RequireAuth は認証済みでないリクエストを 401 で拒否するミドルウェアです。Authenticate の後に使います
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if currentUser(c) == nil {
+			abortUnauthorized(c, CodeUnauthorized, i18n.New("error.unauthorized"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// verifyToken はトークンを検証してユーザーを読み込みます。失敗した場合はエラーを記録して false を返します
+func verifyToken(c *gin.Context, token, kind string) (*models.User, bool) {
+	id, err := tokens.Verify(token, kind)
+	if err != nil {
+		abortUnauthorized(c, CodeInvalidToken, errInvalidToken)
+		return nil, false
+	}
+	// 削除されたユーザーのトークンは受け付けない
+	user, err := store.GetUser(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		abortUnauthorized(c, CodeInvalidToken, errInvalidToken)
+		return nil, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// abortUnauthorized は WWW-Authenticate ヘッダーを付けて 401 を返します
+func abortUnauthorized(c *gin.Context, code string, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="hpcs"`)
+	abortWithError(c, http.StatusUnauthorized, code, err)
+}
+
+// currentUser は認証済みのユーザーを返します。匿名の場合は nil を返します
+func currentUser(c *gin.Context) *models.User {
+	user, _ := c.Get(userKey)
+	u, _ := user.(*models.User)
+	return u
+}
+
+// currentUserID は認証済みのユーザーのIDを返します。匿名の場合は空を返します
+func currentUserID(c *gin.Context) string {
+	if user := currentUser(c); user != nil {
+		return user.ID
+	}
+	return ""
+}
+
+// canAccess は診断結果やセッションを現在のユーザーが参照できるかどうかを返します。
+// 所有者のいないものは誰でも参照でき、所有者のいるものは所有者のみが参照できます
+func canAccess(c *gin.Context, ownerID string) bool {
+	return ownerID == "" || ownerID == currentUserID(c)
+}
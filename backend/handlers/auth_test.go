@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/auth"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupAuth はテスト用のトークンの発行者を設定します
+func setupAuth(t *testing.T) {
+	t.Helper()
+	issuer, err := auth.NewIssuer([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("Failed to create issuer: %v", err)
+	}
+	SetAuth(issuer)
+	t.Cleanup(func() { SetAuth(nil) })
+}
+
+func setupAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), Language(), ErrorHandler(), Authenticate())
+	r.POST("/api/auth/register", Register)
+	r.POST("/api/auth/login", Login)
+	r.POST("/api/auth/refresh", Refresh)
+	r.GET("/api/auth/me", RequireAuth(), GetCurrentUser)
+	r.POST("/api/calculate", CalculateScore)
+	r.GET("/api/results", ListResults)
+	r.GET("/api/results/:id", GetResult)
+	r.GET("/api/results/:id/chart.svg", GetResultChartSVG)
+	r.POST("/api/sessions", CreateSession)
+	r.GET("/api/sessions/:id", GetSession)
+	return r
+}
+
+// doAuthJSON は Bearer トークンを付けてリクエストを送信します（token が空の場合は付けません）
+func doAuthJSON(router *gin.Engine, method, path, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// registerUser はユーザーを登録してトークンを返します
+func registerUser(t *testing.T, router *gin.Engine, email string) authResponse {
+	t.Helper()
+	w := doAuthJSON(router, "POST", "/api/auth/register", `{"email":"`+email+`","password":"password123","name":"Test"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var response authResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return response
+}
+
+func TestAuthFlow(t *testing.T) {
+	setupStore(t)
+	setupAuth(t)
+	router := setupAuthRouter()
+
+	registered := registerUser(t, router, "alice@example.com")
+	if registered.User == nil || registered.User.ID == "" || registered.AccessToken == "" || registered.RefreshToken == "" {
+		t.Fatalf("Unexpected register response: %+v", registered)
+	}
+
+	// ログイン（メールアドレスの大文字と小文字は区別しない）
+	w := doAuthJSON(router, "POST", "/api/auth/login", `{"email":"Alice@Example.com","password":"password123"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "$2a$") {
+		t.Error("Expected password hash not to be returned")
+	}
+	var login authResponse
+	json.Unmarshal(w.Body.Bytes(), &login)
+
+	// アクセストークンでユーザーを取得
+	w = doAuthJSON(router, "GET", "/api/auth/me", "", login.AccessToken)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"email":"alice@example.com"`) {
+		t.Errorf("Unexpected me response %d: %s", w.Code, w.Body.String())
+	}
+
+	// リフレッシュトークンで再発行
+	w = doAuthJSON(router, "POST", "/api/auth/refresh", `{"refreshToken":"`+login.RefreshToken+`"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var refreshed authResponse
+	json.Unmarshal(w.Body.Bytes(), &refreshed)
+	if refreshed.User.ID != registered.User.ID || refreshed.AccessToken == "" {
+		t.Errorf("Unexpected refresh response: %+v", refreshed)
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	setupStore(t)
+	setupAuth(t)
+	router := setupAuthRouter()
+	registered := registerUser(t, router, "bob@example.com")
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "登録済みのメールアドレス",
+			method:         "POST",
+			path:           "/api/auth/register",
+			body:           `{"email":"BOB@example.com","password":"password123"}`,
+			expectedStatus: http.StatusConflict,
+			expectedCode:   CodeEmailTaken,
+		},
+		{
+			name:           "不正なメールアドレス",
+			method:         "POST",
+			path:           "/api/auth/register",
+			body:           `{"email":"bob","password":"password123"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidParameter,
+		},
+		{
+			name:           "短いパスワード",
+			method:         "POST",
+			path:           "/api/auth/register",
+			body:           `{"email":"carol@example.com","password":"short"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidParameter,
+		},
+		{
+			name:           "誤ったパスワード",
+			method:         "POST",
+			path:           "/api/auth/login",
+			body:           `{"email":"bob@example.com","password":"wrong-password"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeInvalidCredentials,
+		},
+		{
+			name:           "未登録のメールアドレス",
+			method:         "POST",
+			path:           "/api/auth/login",
+			body:           `{"email":"nobody@example.com","password":"password123"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeInvalidCredentials,
+		},
+		{
+			name:           "アクセストークンで再発行",
+			method:         "POST",
+			path:           "/api/auth/refresh",
+			body:           `{"refreshToken":"` + registered.AccessToken + `"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeInvalidToken,
+		},
+		{
+			name:           "リフレッシュトークンで認証",
+			method:         "GET",
+			path:           "/api/auth/me",
+			token:          registered.RefreshToken,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeInvalidToken,
+		},
+		{
+			name:           "不正なトークン",
+			method:         "GET",
+			path:           "/api/results",
+			token:          "invalid",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeInvalidToken,
+		},
+		{
+			name:           "未認証",
+			method:         "GET",
+			path:           "/api/auth/me",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doAuthJSON(router, tt.method, tt.path, tt.body, tt.token)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			var problem Problem
+			json.Unmarshal(w.Body.Bytes(), &problem)
+			if problem.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %s", tt.expectedCode, problem.Code)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestAuthDisabled(t *testing.T) {
+	setupStore(t)
+	router := setupAuthRouter()
+
+	w := doAuthJSON(router, "POST", "/api/auth/login", `{"email":"bob@example.com","password":"password123"}`, "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
+func TestResultOwnership(t *testing.T) {
+	setupStore(t)
+	setupAuth(t)
+	router := setupAuthRouter()
+	alice := registerUser(t, router, "alice@example.com")
+	bob := registerUser(t, router, "bob@example.com")
+
+	calculate := func(token string) string {
+		t.Helper()
+		w := doAuthJSON(router, "POST", "/api/calculate", `{"responses":[{"questionId":1,"score":5}]}`, token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response calculateResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response.ID
+	}
+	aliceResult := calculate(alice.AccessToken)
+	anonymousResult := calculate("")
+
+	// 所有者と所有者のいない診断結果は参照できる
+	tests := []struct {
+		name           string
+		path           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "所有者", path: "/api/results/" + aliceResult, token: alice.AccessToken, expectedStatus: http.StatusOK},
+		{name: "他のユーザー", path: "/api/results/" + aliceResult, token: bob.AccessToken, expectedStatus: http.StatusNotFound},
+		{name: "匿名", path: "/api/results/" + aliceResult, expectedStatus: http.StatusNotFound},
+		{name: "所有者のいない診断結果", path: "/api/results/" + anonymousResult, token: bob.AccessToken, expectedStatus: http.StatusOK},
+		{name: "他のユーザーの結果との比較", path: "/api/results/" + anonymousResult + "/chart.svg?compare=" + aliceResult, token: bob.AccessToken, expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doAuthJSON(router, "GET", tt.path, "", tt.token); w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// 一覧は自分の診断結果（匿名の場合は所有者のいない診断結果）のみ
+	lists := map[string]string{alice.AccessToken: aliceResult, bob.AccessToken: "", "": anonymousResult}
+	for token, expected := range lists {
+		var results []struct {
+			ID      string `json:"id"`
+			OwnerID string `json:"ownerId"`
+		}
+		json.Unmarshal(doAuthJSON(router, "GET", "/api/results", "", token).Body.Bytes(), &results)
+		if expected == "" {
+			if len(results) != 0 {
+				t.Errorf("Expected no results, got %+v", results)
+			}
+			continue
+		}
+		if len(results) != 1 || results[0].ID != expected {
+			t.Errorf("Expected only %s, got %+v", expected, results)
+		}
+	}
+
+	// セッションも開始したユーザーのみが参照できる
+	w := doAuthJSON(router, "POST", "/api/sessions", "", alice.AccessToken)
+	var session sessionResponse
+	json.Unmarshal(w.Body.Bytes(), &session)
+	if session.OwnerID != alice.User.ID {
+		t.Errorf("Expected session owner %s, got %s", alice.User.ID, session.OwnerID)
+	}
+	if w := doAuthJSON(router, "GET", "/api/sessions/"+session.ID, "", bob.AccessToken); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
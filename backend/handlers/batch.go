@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"fmt"
+	"hpcs/dataset"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/norms"
+	"hpcs/scoring"
+	"hpcs/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchSize は一括採点で受け付ける回答者数の上限です
+const maxBatchSize = 1000
+
+// batchRow は一括採点の1行分の結果です。採点できた場合は Result、問題がある場合は Errors を持ちます
+type batchRow struct {
+	// Row は1始まりの行番号です（CSV ではヘッダーを除いた行番号）
+	Row        int                `json:"row"`
+	Respondent string             `json:"respondent,omitempty"`
+	Result     *calculateResponse `json:"result,omitempty"`
+	Errors     validation.Errors  `json:"errors,omitempty"`
+	// StorageError は採点できたが保存できなかった場合のメッセージです
+	StorageError string `json:"storageError,omitempty"`
+}
+
+// batchResponse は一括採点のレスポンスです
+type batchResponse struct {
+	InstrumentID string `json:"instrumentId"`
+	Total        int    `json:"total"`
+	Succeeded    int    `json:"succeeded"`
+	Failed       int    `json:"failed"`
+	// Stored は保存した診断結果の件数です（認証していない場合は保存しません）
+	Stored int        `json:"stored"`
+	Rows   []batchRow `json:"rows"`
+}
+
+// CalculateBatch は複数の回答者の回答をまとめて採点するハンドラーです。
+// JSON（dataset.ReadJSON が受け付ける形式）と
+// CSV（text/csv の本文、または multipart/form-data の file）を受け付けます。
+// 問題のある行はその行のエラーとして返し、他の行の採点は続けます。
+// 採点した診断結果は認証済みの場合のみ、そのユーザーのものとしてまとめて保存します
+func CalculateBatch(c *gin.Context) {
+	instrumentID, normSetName := c.Query("instrumentId"), c.Query("normSet")
+	var rows []dataset.Row
+	fromCSV := false
+
+	switch c.ContentType() {
+	case "text/csv", "multipart/form-data":
+		fromCSV = true
+	default:
+		batch, err := dataset.ReadJSON(c.Request.Body)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+			return
+		}
+		if batch.InstrumentID != "" {
+			instrumentID = batch.InstrumentID
+		}
+		if batch.NormSet != "" {
+			normSetName = batch.NormSet
+		}
+		rows = batch.Rows()
+	}
+
+	inst, err := lookupInstrument(instrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+	normSet, err := resolveNormSet(inst, normSetName)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidNormSet, err)
+		return
+	}
+
+	if fromCSV {
+		rows, err = readBatchCSV(c, inst)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidCSV, i18n.New("error.invalid_csv", err.Error()))
+			return
+		}
+	}
+	if len(rows) > maxBatchSize {
+		abortWithError(c, http.StatusRequestEntityTooLarge, CodeBatchTooLarge, i18n.New("error.batch_too_large", len(rows), maxBatchSize))
+		return
+	}
+
+	ownerID, lang := currentUserID(c), instrumentLang(c, inst)
+	response, assessments, err := scoreBatch(inst, normSet, rows, ownerID, lang)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	// 匿名の一括採点は公開の一覧に含まれてしまうため保存しない。
+	// 保存に失敗しても採点の結果は返し、原因は記録のみ行う
+	if store != nil && ownerID != "" {
+		if err := storeBatch(&response, assessments, lang); err != nil {
+			c.Error(err)
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// readBatchCSV はリクエストの本文またはアップロードされたファイルから CSV を読み込みます
+func readBatchCSV(c *gin.Context, inst *models.Instrument) ([]dataset.Row, error) {
+	if c.ContentType() != "multipart/form-data" {
+		return dataset.ReadWide(c.Request.Body, inst)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("csv file is required: %w", err)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return dataset.ReadWide(file, inst)
+}
+
+// scoreBatch は各行を検証して採点します。保存する診断結果は ownerID のユーザーのものになり、
+// 行と同じ順に返します（採点できなかった行は nil）。保存はしません
+func scoreBatch(inst *models.Instrument, normSet *norms.NormSet, rows []dataset.Row, ownerID, lang string) (batchResponse, []*models.Assessment, error) {
+	response := batchResponse{
+		InstrumentID: inst.ID,
+		Total:        len(rows),
+		Rows:         make([]batchRow, 0, len(rows)),
+	}
+	assessments := make([]*models.Assessment, 0, len(rows))
+
+	scorer := scoring.New(inst)
+	for _, row := range rows {
+		result := batchRow{Row: row.Line, Respondent: row.Respondent}
+
+		if errs := row.Validate(inst); len(errs) > 0 {
+			result.Errors = errs.Localize(lang)
+			response.Failed++
+			response.Rows = append(response.Rows, result)
+			assessments = append(assessments, nil)
+			continue
+		}
+
+		scored, assessment, err := scoreSubmission(scorer, scoring.Submission{
+			Responses:    row.Responses,
+			Demographics: row.Demographics,
+			NormSet:      normSet,
+		}, resultOrigin{OwnerID: ownerID})
+		if err != nil {
+			return batchResponse{}, nil, err
+		}
+		scored.localize(inst, lang)
+		result.Result = &scored
+		response.Succeeded++
+		response.Rows = append(response.Rows, result)
+		assessments = append(assessments, assessment)
+	}
+
+	return response, assessments, nil
+}
+
+// storeBatch は採点できた行の診断結果を1つのトランザクションでまとめて保存し、各行に保存した ID を設定します。
+// 保存に失敗した場合はいずれの行も保存されないため、採点できた行に StorageError を設定してエラーを返します
+func storeBatch(response *batchResponse, assessments []*models.Assessment, lang string) error {
+	var stored []*models.Assessment
+	for _, a := range assessments {
+		if a != nil {
+			stored = append(stored, a)
+		}
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	err := store.SaveAssessments(stored)
+	for i, a := range assessments {
+		switch {
+		case a == nil:
+		case err != nil:
+			response.Rows[i].StorageError = i18n.T(lang, "error.result_not_stored")
+		default:
+			response.Rows[i].Result.ID = a.ID
+		}
+	}
+	if err != nil {
+		return err
+	}
+	response.Stored = len(stored)
+	return nil
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"hpcs/dataset"
+	"hpcs/models"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupBatchRouter() *gin.Engine {
+	r := setupRouter()
+	r.POST("/api/calculate/batch", CalculateBatch)
+	return r
+}
+
+// decodeBatch はレスポンスを一括採点の結果として読み込みます
+func decodeBatch(t *testing.T, w *httptest.ResponseRecorder) batchResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response batchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return response
+}
+
+func TestCalculateBatchJSON(t *testing.T) {
+	setupStore(t)
+	router := setupBatchRouter()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "回答者の配列",
+			body: `[
+				{"respondent":"a","responses":[{"questionId":1,"score":5},{"questionId":5,"score":2}]},
+				{"respondent":"b","responses":[{"questionId":1,"score":9}]},
+				{"respondent":"c","responses":[{"questionId":2,"score":3}],"demographics":{"ageGroup":"20s"}}
+			]`,
+		},
+		{
+			name: "検査IDを含むオブジェクト",
+			body: `{"instrumentId":"hpcs-74","respondents":[
+				{"respondent":"a","responses":[{"questionId":1,"score":5},{"questionId":5,"score":2}]},
+				{"respondent":"b","responses":[{"questionId":1,"score":9}]},
+				{"respondent":"c","responses":[{"questionId":2,"score":3}]}
+			]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/calculate/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			response := decodeBatch(t, w)
+			if response.InstrumentID != "hpcs-74" || response.Total != 3 || response.Succeeded != 2 || response.Failed != 1 {
+				t.Fatalf("Unexpected summary: %+v", response)
+			}
+			for i, row := range response.Rows {
+				if row.Row != i+1 {
+					t.Errorf("Expected row %d, got %d", i+1, row.Row)
+				}
+			}
+
+			failed := response.Rows[1]
+			if failed.Respondent != "b" || failed.Result != nil || len(failed.Errors) != 1 {
+				t.Fatalf("Expected a single error for respondent b, got %+v", failed)
+			}
+			if failed.Errors[0].Code != "out_of_range" || failed.Errors[0].Field != "responses[0].score" {
+				t.Errorf("Unexpected error: %+v", failed.Errors[0])
+			}
+
+			// 匿名の一括採点は保存しない
+			for _, i := range []int{0, 2} {
+				row := response.Rows[i]
+				if row.Result == nil || row.Result.ID != "" || len(row.Errors) != 0 {
+					t.Errorf("Expected row %d to be scored but not stored, got %+v", row.Row, row)
+				}
+			}
+			if response.Stored != 0 {
+				t.Errorf("Expected no stored results, got %d", response.Stored)
+			}
+		})
+	}
+}
+
+func TestCalculateBatchStored(t *testing.T) {
+	setupStore(t)
+	setupAuth(t)
+	router := setupAuthRouter()
+	router.POST("/api/calculate/batch", CalculateBatch)
+	owner := registerUser(t, router, "owner@example.com").AccessToken
+
+	body := `[{"responses":[{"questionId":1,"score":5}]},{"responses":[{"questionId":1,"score":9}]},{"responses":[{"questionId":2,"score":3}]}]`
+	response := decodeBatch(t, doAuthJSON(router, "POST", "/api/calculate/batch", body, owner))
+	if response.Stored != 2 || response.Rows[0].Result.ID == "" || response.Rows[2].Result.ID == "" {
+		t.Fatalf("Expected the scored rows to be stored, got %+v", response)
+	}
+
+	// 保存した診断結果は所有者のもので、匿名の一覧には含まれない
+	var listed []models.Assessment
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results", "", owner, http.StatusOK), &listed)
+	if len(listed) != 2 {
+		t.Errorf("Expected 2 results for the owner, got %d", len(listed))
+	}
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results", "", "", http.StatusOK), &listed)
+	if len(listed) != 0 {
+		t.Errorf("Expected no anonymous results, got %d", len(listed))
+	}
+}
+
+func TestStoreBatchFailure(t *testing.T) {
+	s := setupStore(t)
+	response := batchResponse{Rows: []batchRow{{Row: 1, Result: &calculateResponse{}}, {Row: 2}}}
+	assessments := []*models.Assessment{{InstrumentID: "hpcs-74", OwnerID: "owner"}, nil}
+
+	// 保存に失敗した場合は採点できた行ごとに報告する
+	s.Close()
+	if err := storeBatch(&response, assessments, "en"); err == nil {
+		t.Fatal("Expected the storage error to be returned")
+	}
+	if response.Stored != 0 || response.Rows[0].StorageError == "" || response.Rows[0].Result.ID != "" || response.Rows[1].StorageError != "" {
+		t.Errorf("Unexpected rows after the storage failure: %+v", response)
+	}
+}
+
+func TestCalculateBatchCSV(t *testing.T) {
+	router := setupBatchRouter()
+	csv := "respondent,q1,q5,ageGroup\n" +
+		"a,5,2,20s\n" +
+		"b,x,6,\n" +
+		"c,3,,30s\n"
+
+	tests := []struct {
+		name    string
+		request func() *http.Request
+	}{
+		{
+			name: "CSV の本文",
+			request: func() *http.Request {
+				req, _ := http.NewRequest("POST", "/api/calculate/batch?lang=ja", strings.NewReader(csv))
+				req.Header.Set("Content-Type", "text/csv")
+				return req
+			},
+		},
+		{
+			name: "ファイルのアップロード",
+			request: func() *http.Request {
+				var body bytes.Buffer
+				writer := multipart.NewWriter(&body)
+				part, _ := writer.CreateFormFile("file", "responses.csv")
+				part.Write([]byte(csv))
+				writer.Close()
+				req, _ := http.NewRequest("POST", "/api/calculate/batch?lang=ja&instrumentId=hpcs-74", &body)
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+				return req
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.request())
+
+			response := decodeBatch(t, w)
+			if response.Total != 3 || response.Succeeded != 2 || response.Failed != 1 {
+				t.Fatalf("Unexpected summary: %+v", response)
+			}
+
+			failed := response.Rows[1]
+			if failed.Respondent != "b" || len(failed.Errors) != 2 {
+				t.Fatalf("Expected two errors for respondent b, got %+v", failed)
+			}
+			codes := map[string]string{}
+			for _, fe := range failed.Errors {
+				codes[fe.Field] = fe.Code
+			}
+			if codes["q1"] != "invalid_value" || codes["q5"] != "out_of_range" {
+				t.Errorf("Unexpected errors: %+v", failed.Errors)
+			}
+			if !strings.Contains(failed.Errors[0].Message, "質問") {
+				t.Errorf("Expected Japanese error message, got %q", failed.Errors[0].Message)
+			}
+
+			if response.Rows[2].Result == nil || response.Rows[2].Result.Labels["neuroticism"] != "神経症傾向" {
+				t.Errorf("Expected localized result for respondent c, got %+v", response.Rows[2].Result)
+			}
+		})
+	}
+}
+
+func TestCalculateBatchErrors(t *testing.T) {
+	router := setupBatchRouter()
+
+	tooMany := make([]dataset.Respondent, maxBatchSize+1)
+	tooManyBody, _ := json.Marshal(tooMany)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		path           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "不正な JSON",
+			contentType:    "application/json",
+			path:           "/api/calculate/batch",
+			body:           `[{"respondent":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidJSON,
+		},
+		{
+			name:           "存在しない検査",
+			contentType:    "application/json",
+			path:           "/api/calculate/batch?instrumentId=unknown",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeUnknownInstrument,
+		},
+		{
+			name:           "存在しない質問の列",
+			contentType:    "text/csv",
+			path:           "/api/calculate/batch",
+			body:           "q1,q999\n1,2\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidCSV,
+		},
+		{
+			name:           "ファイルのないアップロード",
+			contentType:    "multipart/form-data; boundary=x",
+			path:           "/api/calculate/batch",
+			body:           "--x--\r\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidCSV,
+		},
+		{
+			name:           "上限を超える回答者数",
+			contentType:    "application/json",
+			path:           "/api/calculate/batch",
+			body:           string(tooManyBody),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   CodeBatchTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("Failed to unmarshal problem: %v", err)
+			}
+			if problem.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %s", tt.expectedCode, problem.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxInvitations は1回のリクエストで作成できる招待の上限です
+const maxInvitations = 500
+
+// invitationInfo は招待のトークンから参加者に示す実施の情報です
+type invitationInfo struct {
+	CampaignName string     `json:"campaignName"`
+	InstrumentID string     `json:"instrumentId"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// invitationProgress は招待ごとの回答状況です
+type invitationProgress struct {
+	models.Invitation
+	Started   int  `json:"started"`
+	Completed int  `json:"completed"`
+	Expired   bool `json:"expired"`
+}
+
+// campaignDashboard は実施の回答状況です
+type campaignDashboard struct {
+	CampaignID string `json:"campaignId"`
+	// Invitations は作成した招待の数です
+	Invitations int `json:"invitations"`
+	// Invited は招待した人数です。回数制限のある招待は使用できる回数、無制限の招待は使用された回数で数えます
+	Invited    int `json:"invited"`
+	Started    int `json:"started"`
+	InProgress int `json:"inProgress"`
+	Completed  int `json:"completed"`
+	// CompletionRate は招待した人数に対する回答を完了した人数の割合です
+	CompletionRate float64              `json:"completionRate"`
+	ByInvitation   []invitationProgress `json:"byInvitation"`
+}
+
+// CreateCampaign は組織に実施を作成するハンドラーです。
+// 組織全体の実施は組織の管理者、チーム単位の実施はチームのマネージャーも作成できます
+func CreateCampaign(c *gin.Context) {
+	org, ok := authorizeOrganization(c, false)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Name         string `json:"name"`
+		InstrumentID string `json:"instrumentId"`
+		NormSet      string `json:"normSet"`
+		TeamID       string `json:"teamId"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.name_required"))
+		return
+	}
+	inst, err := lookupInstrument(request.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+	if _, err := resolveNormSet(inst, request.NormSet); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidNormSet, err)
+		return
+	}
+	if request.TeamID != "" {
+		team, ok := loadTeam(c, request.TeamID)
+		if !ok {
+			return
+		}
+		if team.OrganizationID != org.ID {
+			abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.team_not_found", request.TeamID))
+			return
+		}
+	}
+
+	campaign := &models.Campaign{
+		OrganizationID: org.ID,
+		TeamID:         request.TeamID,
+		Name:           name,
+		InstrumentID:   inst.ID,
+		NormSet:        request.NormSet,
+		CreatedBy:      currentUserID(c),
+	}
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return
+	}
+	if !p.CanManageCampaign(*campaign) {
+		abortForbidden(c)
+		return
+	}
+
+	if err := store.CreateCampaign(campaign); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// ListCampaigns は組織の実施のうち、現在のユーザーが管理できるものの一覧を返すハンドラーです
+func ListCampaigns(c *gin.Context) {
+	org, ok := authorizeOrganization(c, false)
+	if !ok {
+		return
+	}
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return
+	}
+
+	campaigns, err := store.ListCampaigns(org.ID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	visible := []models.Campaign{}
+	for _, campaign := range campaigns {
+		if p.CanManageCampaign(campaign) {
+			visible = append(visible, campaign)
+		}
+	}
+	c.JSON(http.StatusOK, visible)
+}
+
+// GetCampaign は実施を返すハンドラーです
+func GetCampaign(c *gin.Context) {
+	campaign, ok := authorizeCampaign(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, campaign)
+}
+
+// authorizeCampaign は実施を読み込み、現在のユーザーが管理できるかを確認します。
+// 見つからない場合と権限がない場合はエラーを記録して false を返します
+func authorizeCampaign(c *gin.Context, id string) (*models.Campaign, bool) {
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return nil, false
+	}
+	campaign, err := store.GetCampaign(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.campaign_not_found", id))
+		return nil, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	if !p.CanManageCampaign(*campaign) {
+		abortForbidden(c)
+		return nil, false
+	}
+	return campaign, true
+}
+
+// CreateInvitations は実施への招待を作成するハンドラーです。
+// emails を指定した場合はメールアドレスごとに、それ以外の場合は count 件の招待を作成します
+func CreateInvitations(c *gin.Context) {
+	campaign, ok := authorizeCampaign(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Count  int      `json:"count"`
+		Emails []string `json:"emails"`
+		// MaxUses は招待ごとに使用できる回数です（省略時は1回、0 は無制限）
+		MaxUses   *int       `json:"maxUses"`
+		ExpiresAt *time.Time `json:"expiresAt"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+
+	count := request.Count
+	if len(request.Emails) > 0 {
+		count = len(request.Emails)
+	} else if count == 0 {
+		count = 1
+	}
+	if count < 0 || count > maxInvitations {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_invitation_count", count, maxInvitations))
+		return
+	}
+	maxUses := 1
+	if request.MaxUses != nil {
+		maxUses = *request.MaxUses
+	}
+	if maxUses < 0 {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_parameter", "maxUses", strconv.Itoa(maxUses)))
+		return
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter,
+			i18n.New("error.invalid_parameter", "expiresAt", request.ExpiresAt.Format(time.RFC3339)))
+		return
+	}
+
+	invitations := make([]*models.Invitation, count)
+	for i := range invitations {
+		invitations[i] = &models.Invitation{
+			CampaignID: campaign.ID,
+			MaxUses:    maxUses,
+			ExpiresAt:  request.ExpiresAt,
+		}
+		if len(request.Emails) > 0 {
+			invitations[i].Email = strings.TrimSpace(request.Emails[i])
+		}
+	}
+	if err := store.CreateInvitations(invitations); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invitations)
+}
+
+// ListInvitations は実施の招待の一覧を返すハンドラーです
+func ListInvitations(c *gin.Context) {
+	campaign, ok := authorizeCampaign(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	invitations, err := store.ListInvitations(campaign.ID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// GetCampaignDashboard は実施の招待、開始と完了の件数を返すハンドラーです
+func GetCampaignDashboard(c *gin.Context) {
+	campaign, ok := authorizeCampaign(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	invitations, err := store.ListInvitations(campaign.ID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	sessions, err := store.ListSessions(campaign.ID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCampaignDashboard(campaign.ID, invitations, sessions, time.Now()))
+}
+
+// newCampaignDashboard は招待とセッションから回答状況を集計します
+func newCampaignDashboard(campaignID string, invitations []models.Invitation, sessions []models.Session, now time.Time) campaignDashboard {
+	dashboard := campaignDashboard{
+		CampaignID:   campaignID,
+		Invitations:  len(invitations),
+		ByInvitation: make([]invitationProgress, len(invitations)),
+	}
+	index := make(map[string]int, len(invitations))
+	for i, inv := range invitations {
+		index[inv.Token] = i
+		dashboard.ByInvitation[i] = invitationProgress{Invitation: inv, Expired: inv.Expired(now)}
+		if inv.MaxUses > 0 {
+			dashboard.Invited += inv.MaxUses
+		} else {
+			dashboard.Invited += inv.Uses
+		}
+	}
+
+	for _, session := range sessions {
+		completed := session.Status == models.SessionCompleted
+		dashboard.Started++
+		if completed {
+			dashboard.Completed++
+		} else {
+			dashboard.InProgress++
+		}
+		if i, ok := index[session.InvitationToken]; ok {
+			dashboard.ByInvitation[i].Started++
+			if completed {
+				dashboard.ByInvitation[i].Completed++
+			}
+		}
+	}
+	if dashboard.Invited > 0 {
+		dashboard.CompletionRate = float64(dashboard.Completed) / float64(dashboard.Invited)
+	}
+	return dashboard
+}
+
+// GetInvitation は招待のトークンから実施の情報を返すハンドラーです（認証は不要です）
+func GetInvitation(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+	invitation, campaign, ok := loadInvitation(c, c.Param("token"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, invitationInfo{
+		CampaignName: campaign.Name,
+		InstrumentID: campaign.InstrumentID,
+		ExpiresAt:    invitation.ExpiresAt,
+	})
+}
+
+// loadInvitation は使用できる招待とその実施を読み込みます。
+// 見つからない場合は 404、有効期限切れや使用済みの場合は 410 を記録して false を返します
+func loadInvitation(c *gin.Context, token string) (*models.Invitation, *models.Campaign, bool) {
+	invitation, err := store.GetInvitation(token)
+	if err != nil {
+		respondInvitationError(c, err)
+		return nil, nil, false
+	}
+	switch {
+	case invitation.Expired(time.Now()):
+		respondInvitationError(c, storage.ErrInvitationExpired)
+		return nil, nil, false
+	case invitation.Exhausted():
+		respondInvitationError(c, storage.ErrInvitationUsed)
+		return nil, nil, false
+	}
+
+	campaign, err := store.GetCampaign(invitation.CampaignID)
+	if err != nil {
+		// 実施が削除された招待は存在しないものとして扱う
+		respondInvitationError(c, err)
+		return nil, nil, false
+	}
+	return invitation, campaign, true
+}
+
+// respondInvitationError は招待の読み込みや使用のエラーをレスポンスに変換します
+func respondInvitationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.invitation_not_found"))
+	case errors.Is(err, storage.ErrInvitationExpired):
+		abortWithError(c, http.StatusGone, CodeInvitationExpired, i18n.New("error.invitation_expired"))
+	case errors.Is(err, storage.ErrInvitationUsed):
+		abortWithError(c, http.StatusGone, CodeInvitationUsed, i18n.New("error.invitation_used"))
+	default:
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpcs/models"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupCampaignsRouter(t *testing.T) *gin.Engine {
+	r := setupOrganizationsRouter(t)
+	r.GET("/api/invitations/:token", GetInvitation)
+	r.PUT("/api/sessions/:id/responses", SaveSessionResponses)
+	r.POST("/api/sessions/:id/complete", CompleteSession)
+	orgs := r.Group("/api", RequireAuth())
+	orgs.GET("/organizations/:id/campaigns", ListCampaigns)
+	orgs.POST("/organizations/:id/campaigns", CreateCampaign)
+	orgs.GET("/campaigns/:id", GetCampaign)
+	orgs.GET("/campaigns/:id/invitations", ListInvitations)
+	orgs.POST("/campaigns/:id/invitations", CreateInvitations)
+	orgs.GET("/campaigns/:id/dashboard", GetCampaignDashboard)
+	return r
+}
+
+func TestCampaigns(t *testing.T) {
+	router := setupCampaignsRouter(t)
+	root := registerSuperadmin(t, router, "root@example.com")
+	admin := registerUser(t, router, "admin@example.com").AccessToken
+	outsider := registerUser(t, router, "outsider@example.com").AccessToken
+
+	var org models.Organization
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/organizations", `{"name":"Acme"}`, root, http.StatusCreated), &org)
+	addMember(t, router, "/api/organizations/"+org.ID, `{"email":"admin@example.com","role":"org_admin"}`, root, admin)
+	addMember(t, router, "/api/organizations/"+org.ID, `{"email":"outsider@example.com"}`, root, outsider)
+
+	// 実施の作成
+	campaignsPath := "/api/organizations/" + org.ID + "/campaigns"
+	expectStatus(t, router, "POST", campaignsPath, `{"name":"新入社員 Q3"}`, outsider, http.StatusForbidden)
+	expectStatus(t, router, "POST", campaignsPath, `{"name":"新入社員 Q3","instrumentId":"unknown"}`, admin, http.StatusBadRequest)
+	expectStatus(t, router, "POST", campaignsPath, `{"name":" "}`, admin, http.StatusBadRequest)
+	var campaign models.Campaign
+	json.Unmarshal(expectStatus(t, router, "POST", campaignsPath, `{"name":"新入社員 Q3"}`, admin, http.StatusCreated), &campaign)
+	if campaign.InstrumentID == "" || campaign.OrganizationID != org.ID {
+		t.Fatalf("Unexpected campaign: %+v", campaign)
+	}
+	var campaigns []models.Campaign
+	json.Unmarshal(expectStatus(t, router, "GET", campaignsPath, "", outsider, http.StatusOK), &campaigns)
+	if len(campaigns) != 0 {
+		t.Errorf("Expected participants not to see campaigns, got %+v", campaigns)
+	}
+
+	// 招待の作成
+	invitationsPath := "/api/campaigns/" + campaign.ID + "/invitations"
+	expectStatus(t, router, "POST", invitationsPath, `{"count":2}`, outsider, http.StatusForbidden)
+	expectStatus(t, router, "POST", invitationsPath, `{"count":501}`, admin, http.StatusBadRequest)
+	expectStatus(t, router, "POST", invitationsPath, `{"maxUses":-1}`, admin, http.StatusBadRequest)
+	expectStatus(t, router, "POST", invitationsPath, `{"expiresAt":"2000-01-01T00:00:00Z"}`, admin, http.StatusBadRequest)
+	var single, multi []models.Invitation
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	json.Unmarshal(expectStatus(t, router, "POST", invitationsPath,
+		`{"emails":["a@example.com","b@example.com"],"expiresAt":"`+expiresAt+`"}`, admin, http.StatusCreated), &single)
+	json.Unmarshal(expectStatus(t, router, "POST", invitationsPath, `{"maxUses":0}`, admin, http.StatusCreated), &multi)
+	if len(single) != 2 || single[0].MaxUses != 1 || single[0].Email != "a@example.com" || single[0].ExpiresAt == nil {
+		t.Fatalf("Unexpected single-use invitations: %+v", single)
+	}
+	if len(multi) != 1 || multi[0].MaxUses != 0 {
+		t.Fatalf("Unexpected multi-use invitation: %+v", multi)
+	}
+
+	// 招待の確認とセッションの開始（認証は不要）
+	var info invitationInfo
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/invitations/"+single[0].Token, "", "", http.StatusOK), &info)
+	if info.CampaignName != "新入社員 Q3" || info.InstrumentID != campaign.InstrumentID {
+		t.Errorf("Unexpected invitation info: %+v", info)
+	}
+	expectStatus(t, router, "GET", "/api/invitations/missing", "", "", http.StatusNotFound)
+
+	start := func(token string, expected int) sessionResponse {
+		t.Helper()
+		var session sessionResponse
+		json.Unmarshal(expectStatus(t, router, "POST", "/api/sessions", `{"invitationToken":"`+token+`"}`, "", expected), &session)
+		return session
+	}
+	first := start(single[0].Token, http.StatusCreated)
+	if first.CampaignID != campaign.ID || first.InvitationToken != single[0].Token {
+		t.Errorf("Expected session to belong to the campaign, got %+v", first.Session)
+	}
+	start(single[0].Token, http.StatusGone)
+	expectStatus(t, router, "GET", "/api/invitations/"+single[0].Token, "", "", http.StatusGone)
+	expectStatus(t, router, "POST", "/api/sessions", `{"invitationToken":"`+single[1].Token+`","instrumentId":"other"}`, "", http.StatusBadRequest)
+	start(multi[0].Token, http.StatusCreated)
+	start(multi[0].Token, http.StatusCreated)
+
+	// 1人が回答を完了する
+	expectStatus(t, router, "PUT", "/api/sessions/"+first.ID+"/responses", `{"responses":[{"questionId":1,"score":5}]}`, "", http.StatusOK)
+	var result calculateResponse
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/sessions/"+first.ID+"/complete", "", "", http.StatusOK), &result)
+
+	// 回答状況: 招待は 1回限り×2 と無制限（2回使用）
+	var dashboard campaignDashboard
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/campaigns/"+campaign.ID+"/dashboard", "", admin, http.StatusOK), &dashboard)
+	expected := fmt.Sprintf("%d/%d/%d/%d/%d", 3, 4, 3, 2, 1)
+	if got := fmt.Sprintf("%d/%d/%d/%d/%d", dashboard.Invitations, dashboard.Invited, dashboard.Started, dashboard.InProgress, dashboard.Completed); got != expected {
+		t.Errorf("Expected invitations/invited/started/inProgress/completed %s, got %s", expected, got)
+	}
+	if !almostEqual(dashboard.CompletionRate, 0.25, 1e-9) {
+		t.Errorf("Expected completion rate 0.25, got %v", dashboard.CompletionRate)
+	}
+	progress := map[string]invitationProgress{}
+	for _, p := range dashboard.ByInvitation {
+		progress[p.Token] = p
+	}
+	if progress[single[0].Token].Completed != 1 || progress[single[1].Token].Started != 0 || progress[multi[0].Token].Started != 2 {
+		t.Errorf("Unexpected progress by invitation: %+v", dashboard.ByInvitation)
+	}
+	expectStatus(t, router, "GET", "/api/campaigns/"+campaign.ID+"/dashboard", "", outsider, http.StatusForbidden)
+	expectStatus(t, router, "GET", "/api/campaigns/missing/dashboard", "", admin, http.StatusNotFound)
+
+	// 実施で回答された診断結果は実施の管理者が一覧できる
+	var listed []models.Assessment
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results?campaignId="+campaign.ID, "", admin, http.StatusOK), &listed)
+	if len(listed) != 1 || listed[0].ID != result.ID || listed[0].CampaignID != campaign.ID {
+		t.Errorf("Expected the campaign result, got %+v", listed)
+	}
+	expectStatus(t, router, "GET", "/api/results?campaignId="+campaign.ID, "", outsider, http.StatusForbidden)
+
+	// 匿名で回答された実施の診断結果は、実施の管理者以外には公開しない
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", admin, http.StatusOK)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", root, http.StatusOK)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", outsider, http.StatusNotFound)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", "", http.StatusNotFound)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID+"/report.pdf", "", "", http.StatusNotFound)
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results", "", "", http.StatusOK), &listed)
+	if len(listed) != 0 {
+		t.Errorf("Expected anonymous callers not to list campaign results, got %+v", listed)
+	}
+	exported := expectStatus(t, router, "GET", "/api/export?format=jsonl", "", "", http.StatusOK)
+	if strings.Contains(string(exported), result.ID) {
+		t.Errorf("Expected anonymous export not to include campaign results: %s", exported)
+	}
+}
+
+func TestCampaignDashboardCounts(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	invitations := []models.Invitation{
+		{Token: "a", MaxUses: 1, Uses: 1},
+		{Token: "b", MaxUses: 5, Uses: 1, ExpiresAt: &past},
+		{Token: "c", MaxUses: 0, Uses: 0},
+	}
+	sessions := []models.Session{
+		{InvitationToken: "a", Status: models.SessionCompleted},
+		{InvitationToken: "b", Status: models.SessionInProgress},
+	}
+
+	dashboard := newCampaignDashboard("x", invitations, sessions, now)
+	if dashboard.Invited != 6 || dashboard.Started != 2 || dashboard.Completed != 1 || dashboard.InProgress != 1 {
+		t.Errorf("Unexpected dashboard: %+v", dashboard)
+	}
+	if !dashboard.ByInvitation[1].Expired || dashboard.ByInvitation[0].Expired {
+		t.Errorf("Expected only invitation b to be expired: %+v", dashboard.ByInvitation)
+	}
+	if !almostEqual(dashboard.CompletionRate, 1.0/6, 1e-9) {
+		t.Errorf("Expected completion rate 1/6, got %v", dashboard.CompletionRate)
+	}
+
+	// 招待がない場合は割合を 0 にする
+	if empty := newCampaignDashboard("x", nil, nil, now); empty.CompletionRate != 0 || empty.ByInvitation == nil {
+		t.Errorf("Unexpected empty dashboard: %+v", empty)
+	}
+}
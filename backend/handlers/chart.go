@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"hpcs/chart"
+	"hpcs/i18n"
+	"hpcs/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetResultChartSVG は保存済みの診断結果のレーダーチャートを SVG で返すハンドラーです
+func GetResultChartSVG(c *gin.Context) {
+	radar, ok := resultRadar(c, false)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := radar.SVG(&buf); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml", buf.Bytes())
+}
+
+// GetResultChartPNG は保存済みの診断結果のレーダーチャートを PNG で返すハンドラーです
+func GetResultChartPNG(c *gin.Context) {
+	radar, ok := resultRadar(c, true)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := radar.PNG(&buf, reportFont); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.Data(http.StatusOK, "image/png", buf.Bytes())
+}
+
+// resultRadar はクエリの指定（size, theme, compare, norms）に従ってレーダーチャートの内容を作成します。
+// raster が true でフォントが設定されていない場合、文字は英語で描きます
+func resultRadar(c *gin.Context, raster bool) (chart.Radar, bool) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return chart.Radar{}, false
+	}
+
+	size := chart.DefaultSize
+	if v := c.Query("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < chart.MinSize || n > chart.MaxSize {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_size", v, chart.MinSize, chart.MaxSize))
+			return chart.Radar{}, false
+		}
+		size = n
+	}
+	theme := chart.Light
+	if v := c.Query("theme"); v != "" {
+		t, ok := chart.ThemeByName(v)
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_theme", v))
+			return chart.Radar{}, false
+		}
+		theme = t
+	}
+	var showNorms bool
+	if v := c.Query("norms"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_parameter", "norms", v))
+			return chart.Radar{}, false
+		}
+		showNorms = b
+	}
+
+	assessment, ok := loadAssessment(c)
+	if !ok {
+		return chart.Radar{}, false
+	}
+	inst, err := lookupInstrument(assessment.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return chart.Radar{}, false
+	}
+
+	lang := instrumentLang(c, inst)
+	if raster && reportFont == "" {
+		lang = i18n.English
+	}
+	localized := inst.Localize(lang)
+
+	radar := chart.Radar{
+		Levels: inst.Scale.Max - inst.Scale.Min,
+		Size:   size,
+		Theme:  theme,
+	}
+	for _, d := range localized.Dimensions {
+		radar.Axes = append(radar.Axes, d.Name)
+	}
+	radar.Series = append(radar.Series, chart.Series{
+		Name:   i18n.T(lang, "chart.this_result"),
+		Values: resultPositions(inst, assessment.Result),
+	})
+
+	// 比較対象の診断結果
+	if id := c.Query("compare"); id != "" {
+		other, ok := loadAssessmentByID(c, id)
+		if !ok {
+			return chart.Radar{}, false
+		}
+		if other.InstrumentID != inst.ID {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter,
+				i18n.New("error.compare_instrument_mismatch", id, other.InstrumentID, inst.ID))
+			return chart.Radar{}, false
+		}
+		radar.Series = append(radar.Series, chart.Series{
+			Name:   i18n.T(lang, "chart.comparison"),
+			Values: resultPositions(inst, other.Result),
+			Dashed: true,
+		})
+	}
+
+	// 規準集団の平均
+	if showNorms {
+		set, _ := resolveNormSet(inst, assessment.NormSet)
+		if set == nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidNormSet, i18n.New("error.no_norm_set", assessment.ID))
+			return chart.Radar{}, false
+		}
+		var ageGroup, gender string
+		if assessment.Demographics != nil {
+			ageGroup, gender = assessment.Demographics.AgeGroup, assessment.Demographics.Gender
+		}
+		values := make([]*float64, len(inst.Dimensions))
+		for i, dimension := range inst.DimensionIDs() {
+			if norm, ok := set.Lookup(dimension, ageGroup, gender); ok {
+				values[i] = scalePosition(inst, norm.Mean)
+			}
+		}
+		radar.Series = append(radar.Series, chart.Series{
+			Name:   i18n.T(lang, "chart.norm_mean"),
+			Values: values,
+			Dashed: true,
+		})
+	}
+
+	return radar, true
+}
+
+// resultPositions は次元ごとのスコアの尺度上の位置を返します
+func resultPositions(inst *models.Instrument, result models.Result) []*float64 {
+	values := make([]*float64, len(inst.Dimensions))
+	for i, dimension := range inst.DimensionIDs() {
+		if score, ok := result.Get(dimension); ok {
+			values[i] = scalePosition(inst, score)
+		}
+	}
+	return values
+}
+
+// scalePosition はスコアの尺度上の位置を 0〜1 で返します
+func scalePosition(inst *models.Instrument, score float64) *float64 {
+	return models.Float64((score - float64(inst.Scale.Min)) / float64(inst.Scale.Max-inst.Scale.Min))
+}
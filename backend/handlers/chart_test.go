@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// storeResult は回答を採点して保存し、結果のIDを返します
+func storeResult(t *testing.T, router http.Handler, body string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.ID == "" {
+		t.Fatalf("Failed to store result: %s", w.Body.String())
+	}
+	return created.ID
+}
+
+func TestGetResultChart(t *testing.T) {
+	setupStore(t)
+	setupNorms(t)
+	router := setupResultsRouter()
+
+	id := storeResult(t, router, `{"responses":[{"questionId":1,"score":5},{"questionId":5,"score":2}]}`)
+	other := storeResult(t, router, `{"responses":[{"questionId":1,"score":2},{"questionId":5,"score":4}]}`)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCode   string
+		contains       []string
+	}{
+		{
+			name:           "既定の設定",
+			path:           "/api/results/" + id + "/chart.svg",
+			expectedStatus: http.StatusOK,
+			contains:       []string{`width="400"`, "神経症傾向", "この結果"},
+		},
+		{
+			name:           "英語・ダークテーマ・大きさ指定",
+			path:           "/api/results/" + id + "/chart.svg?lang=en&theme=dark&size=600",
+			expectedStatus: http.StatusOK,
+			contains:       []string{`width="600"`, "Neuroticism", "#111827"},
+		},
+		{
+			name:           "比較対象と規準集団の平均",
+			path:           "/api/results/" + id + "/chart.svg?lang=en&compare=" + other + "&norms=true",
+			expectedStatus: http.StatusOK,
+			contains:       []string{"Comparison", "Norm mean", `stroke-dasharray`},
+		},
+		{
+			name:           "大きさが範囲外",
+			path:           "/api/results/" + id + "/chart.svg?size=5000",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidParameter,
+		},
+		{
+			name:           "存在しない配色",
+			path:           "/api/results/" + id + "/chart.svg?theme=neon",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidParameter,
+		},
+		{
+			name:           "存在しない比較対象",
+			path:           "/api/results/" + id + "/chart.svg?compare=unknown",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   CodeNotFound,
+		},
+		{
+			name:           "存在しない結果",
+			path:           "/api/results/unknown/chart.svg",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				var problem Problem
+				json.Unmarshal(w.Body.Bytes(), &problem)
+				if problem.Code != tt.expectedCode {
+					t.Errorf("Expected code %s, got %s", tt.expectedCode, problem.Code)
+				}
+				return
+			}
+			if got := w.Header().Get("Content-Type"); got != "image/svg+xml" {
+				t.Errorf("Expected Content-Type image/svg+xml, got %s", got)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("Expected SVG to contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetResultChartPNG(t *testing.T) {
+	setupStore(t)
+	router := setupResultsRouter()
+
+	id := storeResult(t, router, `{"responses":[{"questionId":1,"score":5}]}`)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/results/"+id+"/chart.png?size=200", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Expected Content-Type image/png, got %s", got)
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 200 {
+		t.Errorf("Expected width 200, got %d", img.Bounds().Dx())
+	}
+
+	// 規準値が設定されていない場合は規準集団の平均を描けない
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/results/"+id+"/chart.png?norms=1", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"hpcs/i18n"
+	"hpcs/interpret"
+	"hpcs/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compareSubject は比較する1人です。保存済みの診断結果のIDか、診断結果そのもののどちらかを指定します
+type compareSubject struct {
+	ResultID string         `json:"resultId,omitempty"`
+	Result   *models.Result `json:"result,omitempty"`
+	// Label は注記の文中でこの人を指す名前です（既定は A または B）
+	Label string `json:"label,omitempty"`
+}
+
+// compareResponse は2人の比較のレスポンスです
+type compareResponse struct {
+	InstrumentID string         `json:"instrumentId"`
+	A            compareSubject `json:"a"`
+	B            compareSubject `json:"b"`
+	*interpret.Comparison
+	// Labels は次元IDごとの次元名です（応答する言語に翻訳されます）
+	Labels map[string]string `json:"labels"`
+}
+
+// CompareResults は2人の診断結果を比較し、次元ごとの差、類似度と組み合わせの注記を返すハンドラーです。
+// 保存済みの診断結果は参照できるもののみ指定できます
+func CompareResults(c *gin.Context) {
+	var request struct {
+		InstrumentID string         `json:"instrumentId"`
+		A            compareSubject `json:"a"`
+		B            compareSubject `json:"b"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+
+	// 保存済みの診断結果の検査を優先し、両方の検査が一致することを確認する
+	instrumentID := request.InstrumentID
+	subjects := []*compareSubject{&request.A, &request.B}
+	names := []string{"a", "b"}
+	for i, subject := range subjects {
+		if (subject.ResultID == "") == (subject.Result == nil) {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.compare_subject_required", names[i]))
+			return
+		}
+		if subject.Label == "" {
+			subject.Label = strings.ToUpper(names[i])
+		}
+		if subject.ResultID == "" {
+			continue
+		}
+
+		if store == nil {
+			abortWithStorageDisabled(c)
+			return
+		}
+		assessment, ok := loadAssessmentByID(c, subject.ResultID)
+		if !ok {
+			return
+		}
+		if instrumentID != "" && instrumentID != assessment.InstrumentID {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.instrument_mismatch", instrumentID, assessment.InstrumentID))
+			return
+		}
+		instrumentID = assessment.InstrumentID
+		subject.Result = &assessment.Result
+	}
+
+	inst, err := lookupInstrument(instrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+
+	// 直接指定された診断結果のスコアは尺度の範囲内であること
+	for i, subject := range subjects {
+		for _, dimension := range inst.DimensionIDs() {
+			score, ok := subject.Result.Get(dimension)
+			if ok && (score < float64(inst.Scale.Min) || score > float64(inst.Scale.Max)) {
+				abortWithError(c, http.StatusBadRequest, CodeInvalidParameter,
+					i18n.New("error.invalid_parameter", names[i]+".result."+dimension, strconv.FormatFloat(score, 'f', -1, 64)))
+				return
+			}
+		}
+	}
+
+	lang := instrumentLang(c, inst)
+	c.JSON(http.StatusOK, compareResponse{
+		InstrumentID: inst.ID,
+		A:            compareSubject{ResultID: request.A.ResultID, Label: request.A.Label},
+		B:            compareSubject{ResultID: request.B.ResultID, Label: request.B.Label},
+		Comparison:   interpret.Compare(inst, *request.A.Result, *request.B.Result, request.A.Label, request.B.Label, lang),
+		Labels:       dimensionLabels(inst, lang),
+	})
+}
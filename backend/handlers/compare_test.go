@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/interpret"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompareResults(t *testing.T) {
+	setupStore(t)
+	setupAuth(t)
+	router := setupAuthRouter()
+	router.POST("/api/compare", CompareResults)
+
+	owner := registerUser(t, router, "owner@example.com").AccessToken
+	other := registerUser(t, router, "other@example.com").AccessToken
+
+	var anonymous, owned calculateResponse
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/calculate", `{"responses":[{"questionId":1,"score":5},{"questionId":2,"score":5},{"questionId":3,"score":5},{"questionId":4,"score":5}]}`, "", http.StatusOK), &anonymous)
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/calculate", `{"responses":[{"questionId":1,"score":1},{"questionId":2,"score":1},{"questionId":3,"score":1},{"questionId":4,"score":1}]}`, owner, http.StatusOK), &owned)
+	body := `{"a":{"resultId":"` + anonymous.ID + `","label":"Mentor"},"b":{"resultId":"` + owned.ID + `","label":"Mentee"}}`
+
+	var response compareResponse
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/compare", body, owner, http.StatusOK), &response)
+	if response.InstrumentID == "" || response.A.Label != "Mentor" || response.B.ResultID != owned.ID || response.Comparison == nil {
+		t.Fatalf("Unexpected comparison: %+v", response)
+	}
+	if len(response.Dimensions) == 0 || response.Dimensions[0].Difference == nil || response.Labels[response.Dimensions[0].Dimension] == "" {
+		t.Errorf("Expected the difference and label of the first dimension: %+v", response)
+	}
+
+	// 直接指定した診断結果との比較（既定の名前は A と B）
+	inline := `{"a":{"result":{"neuroticism":1.5,"extraversion":4.5}},"b":{"result":{"neuroticism":4.5,"extraversion":1.5}}}`
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/compare?lang=ja", inline, "", http.StatusOK), &response)
+	if len(response.Notes) != 2 || response.Notes[0].Kind != interpret.NoteComplement || !strings.HasPrefix(response.Notes[0].Text, "BはAより") {
+		t.Errorf("Unexpected notes: %+v", response.Notes)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		token    string
+		expected int
+	}{
+		{name: "他人の診断結果", body: body, token: other, expected: http.StatusNotFound},
+		{name: "存在しない診断結果", body: `{"a":{"resultId":"missing"},"b":{"resultId":"` + anonymous.ID + `"}}`, expected: http.StatusNotFound},
+		{name: "比較する相手がない", body: `{"a":{"resultId":"` + anonymous.ID + `"}}`, expected: http.StatusBadRequest},
+		{name: "IDと診断結果の両方を指定", body: `{"a":{"resultId":"` + anonymous.ID + `","result":{}},"b":{"result":{}}}`, expected: http.StatusBadRequest},
+		{name: "検査が異なる", body: `{"instrumentId":"other","a":{"resultId":"` + anonymous.ID + `"},"b":{"result":{}}}`, expected: http.StatusBadRequest},
+		{name: "未知の検査", body: `{"instrumentId":"unknown","a":{"result":{}},"b":{"result":{}}}`, expected: http.StatusBadRequest},
+		{name: "尺度の範囲外のスコア", body: `{"a":{"result":{"openness":6}},"b":{"result":{}}}`, expected: http.StatusBadRequest},
+		{name: "不正なJSON", body: `{"a":`, expected: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, router, "POST", "/api/compare", tt.body, tt.token, tt.expected)
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"hpcs/i18n"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
@@ -113,9 +118,185 @@ func TestErrorResponseFormat(t *testing.T) {
 		t.Error("Error message should be a string")
 	}
 
-	// Content-Typeがapplication/jsonであることを確認
+	// Content-Typeがapplication/problem+jsonであることを確認
 	contentType := w.Header().Get("Content-Type")
-	if !strings.Contains(contentType, "application/json") {
-		t.Errorf("Expected Content-Type to contain application/json, got %s", contentType)
+	if !strings.Contains(contentType, "application/problem+json") {
+		t.Errorf("Expected Content-Type to contain application/problem+json, got %s", contentType)
+	}
+}
+
+func TestValidationErrorList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	// 範囲外のスコア、存在しない質問ID、矛盾する回答をまとめて送信
+	requestBody := `{"responses":[{"questionId":1,"score":3},{"questionId":999,"score":3},{"questionId":2,"score":7},{"questionId":1,"score":5}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response struct {
+		Error  string `json:"error"`
+		Errors []struct {
+			Field      string `json:"field"`
+			QuestionID int    `json:"questionId"`
+			Code       string `json:"code"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	expected := []string{"responses[1].questionId", "responses[2].score", "responses[3].questionId"}
+	if len(response.Errors) != len(expected) {
+		t.Fatalf("Expected %d field errors, got %d: %s", len(expected), len(response.Errors), w.Body.String())
+	}
+	for i, field := range expected {
+		if response.Errors[i].Field != field {
+			t.Errorf("Expected field %s, got %s", field, response.Errors[i].Field)
+		}
+	}
+	if response.Errors[2].Code != "conflicting" {
+		t.Errorf("Expected conflicting answer error, got %s", response.Errors[2].Code)
+	}
+	if !strings.Contains(response.Error, "invalid question ID: 999") {
+		t.Errorf("Expected summary error message, got %s", response.Error)
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedCode   string
+		expectedFields int
+	}{
+		{
+			name:           "不正なJSON形式",
+			requestBody:    `{"responses":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidJSON,
+		},
+		{
+			name:           "存在しない検査",
+			requestBody:    `{"instrumentId":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeUnknownInstrument,
+		},
+		{
+			name:           "存在しない規準値",
+			requestBody:    `{"normSet":"unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidNormSet,
+		},
+		{
+			name:           "バリデーションエラー",
+			requestBody:    `{"responses":[{"questionId":1,"score":6},{"questionId":999,"score":3}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeValidationFailed,
+			expectedFields: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("Failed to unmarshal problem: %v", err)
+			}
+			if problem.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %s", tt.expectedCode, problem.Code)
+			}
+			if problem.Type != "urn:hpcs:problem:"+tt.expectedCode {
+				t.Errorf("Unexpected problem type: %s", problem.Type)
+			}
+			if problem.Status != tt.expectedStatus || problem.Title != http.StatusText(tt.expectedStatus) {
+				t.Errorf("Unexpected status or title: %d %s", problem.Status, problem.Title)
+			}
+			if problem.Detail == "" || problem.Detail != problem.Error {
+				t.Errorf("Expected detail to match error, got %q and %q", problem.Detail, problem.Error)
+			}
+			if problem.Instance != "/api/calculate" {
+				t.Errorf("Expected instance /api/calculate, got %s", problem.Instance)
+			}
+			if len(problem.Errors) != tt.expectedFields {
+				t.Errorf("Expected %d field errors, got %d", tt.expectedFields, len(problem.Errors))
+			}
+			if problem.RequestID == "" || problem.RequestID != w.Header().Get("X-Request-ID") {
+				t.Errorf("Expected request ID to match header, got %q", problem.RequestID)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := setupRouter()
+
+	// クライアントが指定したリクエストIDはそのまま使用される
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(`{"responses":[{"questionId":1,"score":9}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "client-request-1")
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Request-ID"); got != "client-request-1" {
+		t.Errorf("Expected request ID header client-request-1, got %s", got)
+	}
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if problem.RequestID != "client-request-1" {
+		t.Errorf("Expected request ID client-request-1, got %s", problem.RequestID)
+	}
+}
+
+func TestErrorHandlerUnexpectedError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), ErrorHandler())
+	router.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("boom"))
+	})
+
+	// 原因はサーバーのログにのみ出力される
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/fail", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if problem.Code != CodeInternal {
+		t.Errorf("Expected code %s, got %s", CodeInternal, problem.Code)
+	}
+	if strings.Contains(w.Body.String(), "boom") || problem.Detail != i18n.T("en", "error.internal") {
+		t.Errorf("Expected a generic message, got %s", w.Body.String())
+	}
+	if !strings.Contains(logs.String(), "boom") || !strings.Contains(logs.String(), problem.RequestID) {
+		t.Errorf("Expected the cause to be logged with the request ID, got %q", logs.String())
 	}
 }
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/i18n"
+	"hpcs/storage"
+	"hpcs/validation"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// エラーコード（クライアントはメッセージではなくこのコードで判定します）
+const (
+	CodeInvalidJSON           = "invalid_json"
+	CodeValidationFailed      = "validation_failed"
+	CodeInvalidParameter      = "invalid_parameter"
+	CodeUnknownInstrument     = "unknown_instrument"
+	CodeInvalidNormSet        = "invalid_norm_set"
+	CodeNotFound              = "not_found"
+	CodeSessionCompleted      = "session_completed"
+	CodeStorageDisabled       = "storage_disabled"
+	CodeInternal              = "internal_error"
+	CodeInvalidCSV            = "invalid_csv"
+	CodeBatchTooLarge         = "batch_too_large"
+	CodeEmailTaken            = "email_taken"
+	CodeUnauthorized          = "unauthorized"
+	CodeInvalidCredentials    = "invalid_credentials"
+	CodeInvalidToken          = "invalid_token"
+	CodeAuthDisabled          = "auth_disabled"
+	CodeForbidden             = "forbidden"
+	CodeNotOrganizationMember = "not_organization_member"
+	CodeInvitationExpired     = "invitation_expired"
+	CodeInvitationUsed        = "invitation_used"
+)
+
+// problemContentType は RFC 7807 のエラーレスポンスの Content-Type です
+const problemContentType = "application/problem+json"
+
+// requestIDHeader はリクエストIDを受け渡すヘッダーです
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey はコンテキストにリクエストIDを保存するキーです
+const requestIDKey = "requestId"
+
+// APIError はステータスコードとエラーコードを持つ API のエラーです
+type APIError struct {
+	Status int
+	Code   string
+	Err    error
+}
+
+// Error はエラーメッセージを返します
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap は元のエラーを返します
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Problem は RFC 7807 形式のエラーレスポンスです
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"requestId,omitempty"`
+	// Errors は項目ごとの問題です（バリデーションエラーの場合のみ）
+	Errors validation.Errors `json:"errors,omitempty"`
+	// Error は Detail と同じ内容です（従来のクライアントとの互換性のため）
+	Error string `json:"error"`
+}
+
+// abortWithError はエラーを記録して後続の処理を中断します。レスポンスは ErrorHandler が書き込みます
+func abortWithError(c *gin.Context, status int, code string, err error) {
+	c.Error(&APIError{Status: status, Code: code, Err: err})
+	c.Abort()
+}
+
+// abortWithStorageDisabled は保存先が設定されていない場合のエラーを返します
+func abortWithStorageDisabled(c *gin.Context) {
+	abortWithError(c, http.StatusServiceUnavailable, CodeStorageDisabled, errStorageDisabled)
+}
+
+// RequestID はリクエストごとにIDを割り当てるミドルウェアです。
+// クライアントが X-Request-ID を指定した場合はその値を使います
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = storage.NewID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// ErrorHandler はハンドラーが記録したエラーを problem+json のレスポンスに変換するミドルウェアです。
+// 内部エラーの原因はレスポンスに含めず、リクエストIDとともにサーバーのログに出力します
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			apiErr = &APIError{Status: http.StatusInternalServerError, Code: CodeInternal, Err: err}
+		}
+		if apiErr.Status == http.StatusInternalServerError {
+			log.Printf("request %s: %s %s: %v", c.GetString(requestIDKey), c.Request.Method, c.Request.URL.Path, apiErr.Err)
+		}
+		c.Header("Content-Type", problemContentType)
+		c.JSON(apiErr.Status, newProblem(c, apiErr))
+	}
+}
+
+// newProblem は API のエラーから problem+json のレスポンスを作成します。
+// 内部エラーの場合は保存先などのエラーの内容を返さず、共通のメッセージを返します
+func newProblem(c *gin.Context, apiErr *APIError) Problem {
+	lang := requestLang(c)
+	problem := Problem{
+		Type:      "urn:hpcs:problem:" + apiErr.Code,
+		Title:     http.StatusText(apiErr.Status),
+		Status:    apiErr.Status,
+		Instance:  c.Request.URL.Path,
+		Code:      apiErr.Code,
+		RequestID: c.GetString(requestIDKey),
+	}
+	if apiErr.Status == http.StatusInternalServerError {
+		problem.Detail = i18n.T(lang, "error.internal")
+	} else {
+		problem.Detail = localizeError(apiErr.Err, lang)
+	}
+	problem.Error = problem.Detail
+	var fields validation.Errors
+	if errors.As(apiErr.Err, &fields) {
+		problem.Errors = fields.Localize(lang)
+	}
+	return problem
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpcs/dataset"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 書き出しの形式
+const (
+	// exportCSV は1件1行で属性と次元・下位側面の得点を並べた CSV です
+	exportCSV = "csv"
+	// exportJSONL は保存した診断結果をそのまま1件1行の JSON で並べた JSON Lines です
+	exportJSONL = "jsonl"
+	// exportWide は exportCSV に質問ごとの回答の列（q1..qN）を加えた CSV です
+	exportWide = "wide"
+)
+
+// exportFormats は指定できる書き出しの形式です
+var exportFormats = []string{exportCSV, exportJSONL, exportWide}
+
+// dateLayout は日付のみで指定する場合の形式です
+const dateLayout = "2006-01-02"
+
+// ExportResults は保存済みの診断結果を書き出すハンドラーです。
+// format（csv, jsonl, wide）、instrumentId、作成日時の範囲（from, to）を指定できます。
+// 結果はすべてを読み込まずに、データベースから古い順に一定の件数ずつ読み込み、読み取りのトランザクションを閉じてからレスポンスに書き込みます
+func ExportResults(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	format := c.DefaultQuery("format", exportCSV)
+	if !slices.Contains(exportFormats, format) {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter,
+			i18n.New("error.invalid_format", format, strings.Join(exportFormats, ", ")))
+		return
+	}
+	inst, err := lookupInstrument(c.Query("instrumentId"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+	filter, err := exportFilter(c, inst)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, err)
+		return
+	}
+
+	filter, ok := resultScope(c, filter)
+	if !ok {
+		return
+	}
+
+	// ヘッダーを書き込んだ後のエラーはレスポンスを変えられないため、記録のみ行う
+	switch format {
+	case exportJSONL:
+		startDownload(c, "application/x-ndjson", inst.ID+"-results.jsonl")
+		encoder := json.NewEncoder(c.Writer)
+		err = store.EachAssessment(filter, func(a *models.Assessment) error {
+			return encoder.Encode(a)
+		})
+	default:
+		name := inst.ID + "-results.csv"
+		if format == exportWide {
+			name = inst.ID + "-wide.csv"
+		}
+		startDownload(c, "text/csv; charset=utf-8", name)
+		err = writeTable(c, dataset.Columns(inst, format == exportWide), filter)
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// writeTable は条件に一致する診断結果を古い順に読み込みながら CSV で書き出します
+func writeTable(c *gin.Context, columns []dataset.Column, filter storage.AssessmentFilter) error {
+	w, err := dataset.NewWriter(c.Writer, columns)
+	if err != nil {
+		return err
+	}
+	if err := store.EachAssessment(filter, w.Write); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// GetCodebook は wide 形式の各列の説明（次元、下位側面、逆転項目かどうか、値の範囲）を CSV で返すハンドラーです
+func GetCodebook(c *gin.Context) {
+	inst, err := lookupInstrument(c.Query("instrumentId"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+
+	lang := instrumentLang(c, inst)
+	startDownload(c, "text/csv; charset=utf-8", inst.ID+"-codebook.csv")
+	if err := dataset.WriteCodebook(c.Writer, inst, dataset.Columns(inst, true), lang); err != nil {
+		c.Error(err)
+	}
+}
+
+// exportFilter はクエリパラメータから書き出す診断結果の条件を作成します。
+// to を日付のみで指定した場合はその日の終わりまでを含めます
+func exportFilter(c *gin.Context, inst *models.Instrument) (storage.AssessmentFilter, error) {
+	filter := storage.AssessmentFilter{InstrumentID: inst.ID}
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseDate("from", from, false); err != nil {
+			return filter, err
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseDate("to", to, true); err != nil {
+			return filter, err
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, i18n.New("error.invalid_date_range", c.Query("from"), c.Query("to"))
+	}
+	return filter, nil
+}
+
+// parseDate は YYYY-MM-DD または RFC 3339 形式の日時を解析します。
+// 日付のみの場合は UTC のその日の始まり（end が true の場合は翌日の始まり）を返します
+func parseDate(name, value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, i18n.New("error.invalid_date", name, value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// startDownload はファイルとして保存されるレスポンスのヘッダーを書き込みます
+func startDownload(c *gin.Context, contentType, filename string) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+}
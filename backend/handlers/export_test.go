@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"hpcs/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupExportRouter() *gin.Engine {
+	r := setupRouter()
+	r.GET("/api/export", ExportResults)
+	r.GET("/api/export/codebook", GetCodebook)
+	return r
+}
+
+func TestExportResults(t *testing.T) {
+	s := setupStore(t)
+	router := setupExportRouter()
+
+	for i, day := range []int{1, 2, 3} {
+		a := &models.Assessment{
+			ID:                []string{"jan1", "jan2", "jan3"}[i],
+			InstrumentID:      "hpcs-74",
+			InstrumentVersion: "1.2.0",
+			Responses:         []models.Response{{QuestionID: 1, Score: day}},
+			Result:            models.Result{Neuroticism: models.Float64(float64(day))},
+			CreatedAt:         time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC),
+		}
+		if err := s.SaveAssessment(a); err != nil {
+			t.Fatalf("Failed to save assessment: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		filename    string
+		expectedIDs []string
+	}{
+		{
+			name:        "既定は CSV",
+			query:       "",
+			contentType: "text/csv",
+			filename:    "hpcs-74-results.csv",
+			expectedIDs: []string{"jan1", "jan2", "jan3"},
+		},
+		{
+			name:        "期間の指定（終了日を含む）",
+			query:       "?format=wide&from=2024-01-02&to=2024-01-03",
+			contentType: "text/csv",
+			filename:    "hpcs-74-wide.csv",
+			expectedIDs: []string{"jan2", "jan3"},
+		},
+		{
+			name:        "JSON Lines と RFC 3339 の日時",
+			query:       "?format=jsonl&to=2024-01-02T00:00:00Z",
+			contentType: "application/x-ndjson",
+			filename:    "hpcs-74-results.jsonl",
+			expectedIDs: []string{"jan1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/export"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if !strings.HasPrefix(w.Header().Get("Content-Type"), tt.contentType) {
+				t.Errorf("Expected content type %s, got %s", tt.contentType, w.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(w.Header().Get("Content-Disposition"), tt.filename) {
+				t.Errorf("Expected filename %s, got %s", tt.filename, w.Header().Get("Content-Disposition"))
+			}
+
+			var ids []string
+			if tt.contentType == "application/x-ndjson" {
+				for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+					var a models.Assessment
+					if err := json.Unmarshal([]byte(line), &a); err != nil {
+						t.Fatalf("Failed to unmarshal line %q: %v", line, err)
+					}
+					ids = append(ids, a.ID)
+				}
+			} else {
+				records, err := csv.NewReader(w.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse csv: %v", err)
+				}
+				header := strings.Join(records[0], ",")
+				if strings.Contains(tt.query, "wide") != strings.Contains(header, ",q74,") {
+					t.Errorf("Unexpected item columns in header: %s", header)
+				}
+				for _, record := range records[1:] {
+					ids = append(ids, record[0])
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expectedIDs, ",") {
+				t.Errorf("Expected %v, got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestExportResultsErrors(t *testing.T) {
+	setupStore(t)
+	router := setupExportRouter()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"不正な形式", "?format=xlsx", http.StatusBadRequest, CodeInvalidParameter},
+		{"存在しない検査", "?instrumentId=unknown", http.StatusBadRequest, CodeUnknownInstrument},
+		{"不正な日付", "?from=2024/01/01", http.StatusBadRequest, CodeInvalidParameter},
+		{"開始日時が終了日時より後", "?from=2024-02-01&to=2024-01-01", http.StatusBadRequest, CodeInvalidParameter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/export"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var problem Problem
+			json.Unmarshal(w.Body.Bytes(), &problem)
+			if problem.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %s", tt.expectedCode, problem.Code)
+			}
+		})
+	}
+}
+
+func TestExportResultsWithoutStore(t *testing.T) {
+	router := setupExportRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/export", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+func TestGetCodebook(t *testing.T) {
+	router := setupExportRouter()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"検査の言語", "", "感情的に不安定である"},
+		{"英語", "?lang=en", "Neuroticism"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/export/codebook"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expected) {
+				t.Errorf("Expected codebook to contain %q", tt.expected)
+			}
+			records, err := csv.NewReader(w.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("Failed to parse codebook: %v", err)
+			}
+			reversed := 0
+			for _, record := range records[1:] {
+				if record[1] == "item" && record[5] == "1" {
+					reversed++
+				}
+			}
+			if reversed == 0 {
+				t.Error("Expected codebook to mark reverse-keyed items")
+			}
+		})
+	}
+}
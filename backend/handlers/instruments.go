@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"hpcs/data"
+	"hpcs/i18n"
+	"hpcs/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// instruments はスコア計算とバリデーションに使用する検査定義です
+var instruments = mustLoadDefaultInstruments()
+
+// mustLoadDefaultInstruments は同梱の検査定義を読み込みます
+func mustLoadDefaultInstruments() *models.InstrumentSet {
+	set, err := data.LoadInstrumentSet("", "")
+	if err != nil {
+		panic("failed to load default instruments: " + err.Error())
+	}
+	return set
+}
+
+// SetInstruments はハンドラーが使用する検査定義を差し替えます
+func SetInstruments(set *models.InstrumentSet) {
+	instruments = set
+}
+
+// lookupInstrument は指定したIDの検査定義を返します。IDが空の場合はデフォルトの検査を返します
+func lookupInstrument(id string) (*models.Instrument, error) {
+	inst, ok := instruments.Get(id)
+	if !ok {
+		return nil, i18n.New("error.unknown_instrument", id)
+	}
+	return inst, nil
+}
+
+// instrumentSummary は検査一覧で返す検査の概要です
+type instrumentSummary struct {
+	ID         string             `json:"id"`
+	Version    string             `json:"version"`
+	Name       string             `json:"name"`
+	Scale      models.Scale       `json:"scale"`
+	Dimensions []models.Dimension `json:"dimensions"`
+	ItemCount  int                `json:"itemCount"`
+}
+
+// GetInstruments は利用可能な検査の一覧を返すハンドラーです
+func GetInstruments(c *gin.Context) {
+	list := instruments.List()
+	summaries := make([]instrumentSummary, len(list))
+	for i, inst := range list {
+		inst = inst.Localize(requestLang(c))
+		summaries[i] = instrumentSummary{
+			ID:         inst.ID,
+			Version:    inst.Version,
+			Name:       inst.Name,
+			Scale:      inst.Scale,
+			Dimensions: inst.Dimensions,
+			ItemCount:  inst.Bank().Len(),
+		}
+	}
+	c.JSON(http.StatusOK, summaries)
+}
+
+// GetQuestions は質問項目の一覧を返すハンドラーです
+func GetQuestions(c *gin.Context) {
+	inst, err := lookupInstrument(c.Query("instrumentId"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+	c.JSON(http.StatusOK, inst.Localize(requestLang(c)).Bank().Questions())
+}
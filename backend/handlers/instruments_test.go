@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/models"
+	"hpcs/scoring"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetQuestionsAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(RequestID(), Language(), ErrorHandler())
+	router.GET("/api/questions", GetQuestions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/questions", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var questions []models.Question
+	if err := json.Unmarshal(w.Body.Bytes(), &questions); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(questions) != 74 {
+		t.Fatalf("Expected 74 questions, got %d", len(questions))
+	}
+
+	// ID順に並んでいること
+	for i, q := range questions {
+		if q.ID != i+1 {
+			t.Errorf("Expected question at index %d to have ID %d, got %d", i, i+1, q.ID)
+		}
+		if q.Text == "" {
+			t.Errorf("Question %d has empty text", q.ID)
+		}
+	}
+
+	// 逆転項目の確認
+	if !questions[28].IsReverse || questions[28].Category != "neuroticism" {
+		t.Errorf("Expected question 29 to be a reversed neuroticism item, got %+v", questions[28])
+	}
+}
+
+func TestGetQuestionsUnknownInstrument(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(RequestID(), Language(), ErrorHandler())
+	router.GET("/api/questions", GetQuestions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/questions?instrumentId=unknown", nil)
+	router.ServeHTTP(w, req)
+
+	// 他のエンドポイントと同じく、存在しない検査の指定は不正なリクエストとして扱う
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if problem.Code != CodeUnknownInstrument {
+		t.Errorf("Expected code %s, got %s", CodeUnknownInstrument, problem.Code)
+	}
+}
+
+// setupShortForm は4問の短縮版を追加した検査定義に差し替えます
+func setupShortForm(t *testing.T) {
+	original := instruments
+	t.Cleanup(func() { SetInstruments(original) })
+
+	short, err := models.ParseInstrument([]byte(`
+id: short-4
+version: "0.1.0"
+scale: {min: 1, max: 7}
+dimensions:
+  - id: neuroticism
+  - id: teamwork
+items:
+  - {id: 1, text: 心配性である, category: neuroticism}
+  - {id: 2, text: ストレスに強い, category: neuroticism, isReverse: true}
+  - {id: 3, text: 協力的である, category: teamwork}
+  - {id: 4, text: 一人で作業したい, category: teamwork, isReverse: true}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+
+	set, err := models.NewInstrumentSet(append(original.List(), short), original.Default().ID)
+	if err != nil {
+		t.Fatalf("Failed to create instrument set: %v", err)
+	}
+	SetInstruments(set)
+}
+
+func TestCalculateScoreWithInstrument(t *testing.T) {
+	setupShortForm(t)
+	router := setupRouter()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+	}{
+		{
+			name:           "短縮版の採点",
+			requestBody:    `{"instrumentId":"short-4","responses":[{"questionId":1,"score":7},{"questionId":2,"score":3},{"questionId":3,"score":6},{"questionId":4,"score":6}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "短縮版に存在しない質問ID",
+			requestBody:    `{"instrumentId":"short-4","responses":[{"questionId":5,"score":3}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "存在しない検査",
+			requestBody:    `{"instrumentId":"unknown","responses":[]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var result models.Result
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			// 7段階尺度の逆転項目は 8 - score で反転される
+			if score, _ := result.Get("neuroticism"); !almostEqual(score, 6.0, 0.01) {
+				t.Errorf("Expected neuroticism score to be 6.0, got %f", score)
+			}
+			if score, _ := result.Get("teamwork"); !almostEqual(score, 4.0, 0.01) {
+				t.Errorf("Expected teamwork score to be 4.0, got %f", score)
+			}
+		})
+	}
+}
+
+func TestValidateResponsesUsesInstrumentScale(t *testing.T) {
+	setupShortForm(t)
+	short, _ := instruments.Get("short-4")
+
+	if err := scoring.New(short).Validate([]models.Response{{QuestionID: 1, Score: 7}}); err != nil {
+		t.Errorf("Expected score 7 to be valid on a 7-point scale, got %v", err)
+	}
+	if err := scoring.New(instruments.Default()).Validate([]models.Response{{QuestionID: 1, Score: 7}}); err == nil {
+		t.Error("Expected score 7 to be rejected on a 5-point scale")
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/i18n"
+	"hpcs/interpret"
+	"hpcs/models"
+	"hpcs/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// langKey はコンテキストに応答する言語を保存するキーです
+const langKey = "lang"
+
+// Language は lang パラメータまたは Accept-Language ヘッダーから応答する言語を決めるミドルウェアです
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(langKey, i18n.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// requestLang は応答する言語を返します。指定がない場合は空文字列です
+func requestLang(c *gin.Context) string {
+	return c.GetString(langKey)
+}
+
+// instrumentLang は検査の結果を応答する言語を返します。指定がない場合は検査定義の言語です。
+// 次元名（検査定義）と解釈文（翻訳）が異なる言語にならないよう、検査に関する応答ではこちらを使います
+func instrumentLang(c *gin.Context, inst *models.Instrument) string {
+	if lang := requestLang(c); lang != "" {
+		return lang
+	}
+	return inst.Language
+}
+
+// localizeError はエラーメッセージを指定した言語に翻訳します
+func localizeError(err error, lang string) string {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return fields.Localize(lang).Error()
+	}
+	return i18n.Localize(err, lang)
+}
+
+// localize は次元名、規準参照得点の水準の説明と解釈文を指定した言語で設定します
+func (r *calculateResponse) localize(inst *models.Instrument, lang string) {
+	r.Labels = dimensionLabels(inst, lang)
+
+	if r.Norms != nil {
+		for dimension, score := range r.Norms.Dimensions {
+			score.Description = i18n.T(lang, "band."+score.Band)
+			r.Norms.Dimensions[dimension] = score
+		}
+	}
+
+	r.Interpretation = interpret.Interpret(inst, r.Result, r.Norms, lang)
+}
+
+// dimensionLabels は次元IDごとの次元名を指定した言語で返します。名前のない次元はIDを使います
+func dimensionLabels(inst *models.Instrument, lang string) map[string]string {
+	localized := inst.Localize(lang)
+	labels := make(map[string]string, len(localized.Dimensions))
+	for _, d := range localized.Dimensions {
+		labels[d.ID] = d.Name
+		if d.Name == "" {
+			labels[d.ID] = d.ID
+		}
+	}
+	return labels
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetQuestionsLocalized(t *testing.T) {
+	router := setupRouter()
+	router.GET("/api/questions", GetQuestions)
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptLanguage string
+		expectedText   string
+	}{
+		{name: "指定なし", path: "/api/questions", expectedText: "感情的に不安定である"},
+		{name: "langパラメータ", path: "/api/questions?lang=en", acceptLanguage: "ja", expectedText: "I am emotionally unstable"},
+		{name: "Accept-Language", path: "/api/questions", acceptLanguage: "en-US,en;q=0.9", expectedText: "I am emotionally unstable"},
+		{name: "元の言語", path: "/api/questions?lang=ja", expectedText: "感情的に不安定である"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			router.ServeHTTP(w, req)
+
+			var questions []models.Question
+			if err := json.Unmarshal(w.Body.Bytes(), &questions); err != nil {
+				t.Fatalf("Failed to unmarshal questions: %v", err)
+			}
+			if len(questions) != 74 || questions[0].Text != tt.expectedText {
+				t.Errorf("Expected first question %q, got %q", tt.expectedText, questions[0].Text)
+			}
+		})
+	}
+}
+
+func TestCalculateScoreLocalized(t *testing.T) {
+	setupNorms(t)
+	router := setupRouter()
+
+	body := `{"responses":[{"questionId":1,"score":4},{"questionId":2,"score":4},{"questionId":3,"score":4},{"questionId":4,"score":4},` +
+		`{"questionId":5,"score":3},{"questionId":6,"score":3},{"questionId":7,"score":3},{"questionId":8,"score":3},{"questionId":22,"score":3}]}`
+
+	var en, ja, unspecified calculateResponse
+	json.Unmarshal(doJSON(router, "POST", "/api/calculate?lang=en", body).Body.Bytes(), &en)
+	json.Unmarshal(doJSON(router, "POST", "/api/calculate?lang=ja", body).Body.Bytes(), &ja)
+	json.Unmarshal(doJSON(router, "POST", "/api/calculate", body).Body.Bytes(), &unspecified)
+
+	if en.Labels["neuroticism"] != "Neuroticism" || ja.Labels["neuroticism"] != "神経症傾向" {
+		t.Errorf("Unexpected labels: en=%v ja=%v", en.Labels, ja.Labels)
+	}
+	if en.Norms == nil || ja.Norms == nil {
+		t.Fatal("Expected norms in response")
+	}
+	// 神経症傾向は平均 3.0、SD 0.5 に対して 4.0 なので T=70
+	if got := en.Norms.Dimensions["neuroticism"].Description; !strings.HasPrefix(got, "Very high") {
+		t.Errorf("Unexpected English band description: %s", got)
+	}
+	if got := ja.Norms.Dimensions["neuroticism"].Description; !strings.HasPrefix(got, "非常に高い") {
+		t.Errorf("Unexpected Japanese band description: %s", got)
+	}
+
+	// 指定がない場合は次元名も説明も検査定義の言語（日本語）にそろえる
+	if unspecified.Labels["neuroticism"] != "神経症傾向" || unspecified.Norms.Dimensions["neuroticism"].Description != ja.Norms.Dimensions["neuroticism"].Description {
+		t.Errorf("Expected the instrument language, got labels %v and norms %+v", unspecified.Labels, unspecified.Norms)
+	}
+	if unspecified.Interpretation == nil || ja.Interpretation == nil || unspecified.Interpretation.Dimensions[0].Text != ja.Interpretation.Dimensions[0].Text {
+		t.Errorf("Expected the Japanese interpretation, got %+v", unspecified.Interpretation)
+	}
+}
+
+func TestErrorMessagesLocalized(t *testing.T) {
+	router := setupRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/calculate", strings.NewReader(`{"responses":[{"questionId":999,"score":3}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "ja-JP")
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to unmarshal problem: %v", err)
+	}
+	if problem.Detail != "存在しない質問IDです: 999" || problem.Error != problem.Detail {
+		t.Errorf("Unexpected localized detail: %s", problem.Detail)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Message != "存在しない質問IDです: 999" {
+		t.Errorf("Unexpected localized field errors: %+v", problem.Errors)
+	}
+	// コードは言語によらない
+	if problem.Code != CodeValidationFailed {
+		t.Errorf("Expected code %s, got %s", CodeValidationFailed, problem.Code)
+	}
+
+	w = doJSON(router, "POST", "/api/calculate?lang=ja", `{"instrumentId":"unknown"}`)
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if problem.Detail != "検査が見つかりません: unknown" {
+		t.Errorf("Unexpected localized detail: %s", problem.Detail)
+	}
+}
+
+func TestCalculateScoreInterpretation(t *testing.T) {
+	router := setupRouter()
+
+	// 外向性の質問に高く、神経症傾向の質問に低く回答
+	body := `{"responses":[{"questionId":1,"score":1},{"questionId":2,"score":2},{"questionId":3,"score":1},{"questionId":4,"score":2},` +
+		`{"questionId":5,"score":5},{"questionId":6,"score":4},{"questionId":7,"score":5},{"questionId":8,"score":4},{"questionId":22,"score":5}]}`
+
+	var response calculateResponse
+	json.Unmarshal(doJSON(router, "POST", "/api/calculate?lang=ja", body).Body.Bytes(), &response)
+
+	if response.Interpretation == nil {
+		t.Fatal("Expected interpretation in response")
+	}
+	if len(response.Interpretation.Dimensions) != 2 {
+		t.Fatalf("Expected 2 dimension paragraphs, got %+v", response.Interpretation.Dimensions)
+	}
+	if p := response.Interpretation.Dimensions[0]; p.Dimension != "neuroticism" || p.Level != "low" || !strings.Contains(p.Text, "落ち着いて") {
+		t.Errorf("Unexpected neuroticism paragraph: %+v", p)
+	}
+	if len(response.Interpretation.Combinations) != 1 || response.Interpretation.Combinations[0].Combination != "calm_leader" {
+		t.Errorf("Unexpected combinations: %+v", response.Interpretation.Combinations)
+	}
+}
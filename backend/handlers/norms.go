@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/norms"
+)
+
+// normSets は規準参照得点の計算に使用する規準値です（nil の場合は計算しません）
+var normSets *norms.Registry
+
+// SetNorms はハンドラーが使用する規準値を設定します
+func SetNorms(registry *norms.Registry) {
+	normSets = registry
+}
+
+// resolveNormSet は使用する規準値を返します。
+// 名前が空の場合は検査に対応する規準値を使い、見つからなければ nil を返します
+func resolveNormSet(inst *models.Instrument, name string) (*norms.NormSet, error) {
+	if normSets == nil {
+		if name != "" {
+			return nil, i18n.New("error.unknown_norm_set", name)
+		}
+		return nil, nil
+	}
+
+	if name == "" {
+		set, _ := normSets.ForInstrument(inst.ID)
+		return set, nil
+	}
+
+	set, ok := normSets.Get(name)
+	if !ok {
+		return nil, i18n.New("error.unknown_norm_set", name)
+	}
+	if set.InstrumentID != inst.ID {
+		return nil, i18n.New("error.norm_set_mismatch", name, inst.ID)
+	}
+	return set, nil
+}
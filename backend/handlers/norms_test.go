@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/norms"
+	"net/http"
+	"testing"
+)
+
+// setupNorms はテスト用の規準値を設定します
+func setupNorms(t *testing.T) {
+	t.Helper()
+	registry, err := norms.NewRegistry([]*norms.NormSet{
+		{
+			Name:         "general",
+			InstrumentID: "hpcs-74",
+			Dimensions: map[string]norms.DimensionNorm{
+				"neuroticism":  {Mean: 3.0, SD: 0.5},
+				"extraversion": {Mean: 3.0, SD: 1.0},
+			},
+			Groups: []norms.Group{
+				{AgeGroup: "20s", Dimensions: map[string]norms.DimensionNorm{"neuroticism": {Mean: 4.0, SD: 0.5}}},
+			},
+		},
+		{Name: "other", InstrumentID: "short-4"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+	SetNorms(registry)
+	t.Cleanup(func() { SetNorms(nil) })
+}
+
+// 神経症傾向の4項目に4点、外向性の5項目に2点で回答する（いずれも項目の半数）
+const (
+	neuroticism4  = `{"questionId":1,"score":4},{"questionId":2,"score":4},{"questionId":3,"score":4},{"questionId":4,"score":4}`
+	extraversion2 = `{"questionId":5,"score":2},{"questionId":6,"score":2},{"questionId":7,"score":2},{"questionId":8,"score":2},{"questionId":22,"score":2}`
+)
+
+type normsResponse struct {
+	Norms *norms.Profile `json:"norms"`
+}
+
+func TestCalculateScoreWithNorms(t *testing.T) {
+	router := setupRouter()
+
+	// 規準値が未設定の場合は省略される
+	w := doJSON(router, "POST", "/api/calculate", `{"responses":[{"questionId":1,"score":4}]}`)
+	var response normsResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Norms != nil {
+		t.Errorf("Expected no norms without norm sets, got %+v", response.Norms)
+	}
+
+	setupNorms(t)
+
+	// 検査に対応する規準値が自動的に選ばれる
+	w = doJSON(router, "POST", "/api/calculate", `{"responses":[`+neuroticism4+`,`+extraversion2+`]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Norms == nil || response.Norms.NormSet != "general" {
+		t.Fatalf("Expected general norm set, got %+v", response.Norms)
+	}
+	neuroticism := response.Norms.Dimensions["neuroticism"]
+	if neuroticism.Raw != 4 || neuroticism.T != 70 || neuroticism.Band != norms.BandVeryHigh {
+		t.Errorf("Unexpected neuroticism score: %+v", neuroticism)
+	}
+	if extraversion := response.Norms.Dimensions["extraversion"]; extraversion.Z != -1 || extraversion.Band != norms.BandLow {
+		t.Errorf("Unexpected extraversion score: %+v", extraversion)
+	}
+	if _, exists := response.Norms.Dimensions["openness"]; exists {
+		t.Error("Expected dimensions without norms to be omitted")
+	}
+
+	// 属性に応じたグループの規準値が使われる
+	w = doJSON(router, "POST", "/api/calculate",
+		`{"normSet":"general","demographics":{"ageGroup":"20s"},"responses":[`+neuroticism4+`]}`)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if got := response.Norms.Dimensions["neuroticism"]; got.T != 50 || response.Norms.AgeGroup != "20s" {
+		t.Errorf("Expected 20s group norm to be applied, got %+v", response.Norms)
+	}
+}
+
+func TestCalculateScoreNormSetErrors(t *testing.T) {
+	setupNorms(t)
+	router := setupRouter()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "存在しない規準値", body: `{"normSet":"unknown","responses":[]}`},
+		{name: "検査が異なる規準値", body: `{"normSet":"other","responses":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doJSON(router, "POST", "/api/calculate", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/auth"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memberResponse は所属とユーザーの情報です
+type memberResponse struct {
+	models.Membership
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// memberRequest は所属を追加または更新するリクエストです。ユーザーは userId または email で指定します
+type memberRequest struct {
+	UserID string      `json:"userId"`
+	Email  string      `json:"email"`
+	Role   models.Role `json:"role"`
+}
+
+// bindName は名前だけのリクエストを読み込みます。失敗した場合はエラーを記録して false を返します
+func bindName(c *gin.Context) (string, bool) {
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return "", false
+	}
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.name_required"))
+		return "", false
+	}
+	return name, true
+}
+
+// principalOrAbort は現在のユーザーの権限を返します。読み込みに失敗した場合はエラーを記録して false を返します
+func principalOrAbort(c *gin.Context) (auth.Principal, bool) {
+	p, err := currentPrincipal(c)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return auth.Principal{}, false
+	}
+	return p, true
+}
+
+// CreateOrganization は組織を作成するハンドラーです（システム全体の管理者のみ）
+func CreateOrganization(c *gin.Context) {
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return
+	}
+	if !p.Superadmin {
+		abortForbidden(c)
+		return
+	}
+	name, ok := bindName(c)
+	if !ok {
+		return
+	}
+
+	org := &models.Organization{Name: name}
+	if err := store.CreateOrganization(org); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// ListOrganizations は所属している組織の一覧を返すハンドラーです（システム全体の管理者にはすべての組織を返します）
+func ListOrganizations(c *gin.Context) {
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return
+	}
+
+	organizations, err := store.ListOrganizations()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	visible := []models.Organization{}
+	for _, org := range organizations {
+		if p.IsOrgMember(org.ID) {
+			visible = append(visible, org)
+		}
+	}
+	c.JSON(http.StatusOK, visible)
+}
+
+// GetOrganization は組織を返すハンドラーです（組織のメンバーのみ）
+func GetOrganization(c *gin.Context) {
+	org, ok := authorizeOrganization(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// authorizeOrganization はパスの ID の組織を読み込み、現在のユーザーの権限を確認します。
+// admin が true の場合は組織の管理者、false の場合は組織のメンバーであることを求めます
+func authorizeOrganization(c *gin.Context, admin bool) (*models.Organization, bool) {
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return nil, false
+	}
+	org, ok := loadOrganization(c, c.Param("id"))
+	if !ok {
+		return nil, false
+	}
+	if (admin && !p.IsOrgAdmin(org.ID)) || !p.IsOrgMember(org.ID) {
+		abortForbidden(c)
+		return nil, false
+	}
+	return org, true
+}
+
+// ListTeams は組織のチームの一覧を返すハンドラーです（組織のメンバーのみ）
+func ListTeams(c *gin.Context) {
+	org, ok := authorizeOrganization(c, false)
+	if !ok {
+		return
+	}
+	teams, err := store.ListTeams(org.ID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+// CreateTeam は組織にチームを作成するハンドラーです（組織の管理者のみ）
+func CreateTeam(c *gin.Context) {
+	org, ok := authorizeOrganization(c, true)
+	if !ok {
+		return
+	}
+	name, ok := bindName(c)
+	if !ok {
+		return
+	}
+
+	team := &models.Team{OrganizationID: org.ID, Name: name}
+	if err := store.CreateTeam(team); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusCreated, team)
+}
+
+// ListOrganizationMembers は組織とそのチームへの所属の一覧を返すハンドラーです（組織の管理者のみ）
+func ListOrganizationMembers(c *gin.Context) {
+	org, ok := authorizeOrganization(c, true)
+	if !ok {
+		return
+	}
+	respondMembers(c, storage.MembershipFilter{OrganizationID: org.ID})
+}
+
+// SaveOrganizationMember は組織への所属を追加または更新するハンドラーです（組織の管理者のみ）
+func SaveOrganizationMember(c *gin.Context) {
+	org, ok := authorizeOrganization(c, true)
+	if !ok {
+		return
+	}
+	saveMember(c, models.Membership{OrganizationID: org.ID})
+}
+
+// DeleteOrganizationMember は組織への所属を削除するハンドラーです（組織の管理者のみ）。
+// チームへの所属は削除しません
+func DeleteOrganizationMember(c *gin.Context) {
+	org, ok := authorizeOrganization(c, true)
+	if !ok {
+		return
+	}
+	deleteMember(c, org.ID, "")
+}
+
+// AcceptOrganizationMembership は組織への自分の所属を承諾するハンドラーです。
+// 承諾すると組織の管理者が自分の診断結果を参照できるようになります
+func AcceptOrganizationMembership(c *gin.Context) {
+	org, ok := loadOrganization(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	acceptMembership(c, org.ID, "")
+}
+
+// GetTeam はチームを返すハンドラーです（チームのメンバーと組織の管理者のみ）
+func GetTeam(c *gin.Context) {
+	team, ok := authorizeTeam(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// authorizeTeam はパスの ID のチームを読み込み、現在のユーザーの権限を確認します。
+// manage が true の場合はチームのマネージャーまたは組織の管理者、false の場合はチームのメンバーであることを求めます
+func authorizeTeam(c *gin.Context, manage bool) (*models.Team, bool) {
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return nil, false
+	}
+	team, ok := loadTeam(c, c.Param("id"))
+	if !ok {
+		return nil, false
+	}
+	if (manage && !p.CanManageTeam(*team)) || !p.IsTeamMember(*team) {
+		abortForbidden(c)
+		return nil, false
+	}
+	return team, true
+}
+
+// ListTeamMembers はチームへの所属の一覧を返すハンドラーです（チームのマネージャーと組織の管理者のみ）
+func ListTeamMembers(c *gin.Context) {
+	team, ok := authorizeTeam(c, true)
+	if !ok {
+		return
+	}
+	respondMembers(c, storage.MembershipFilter{TeamID: team.ID})
+}
+
+// SaveTeamMember はチームへの所属を追加または更新するハンドラーです。
+// チームのマネージャーは参加者の追加のみ、組織の管理者はマネージャーの指名もできます
+func SaveTeamMember(c *gin.Context) {
+	team, ok := authorizeTeam(c, true)
+	if !ok {
+		return
+	}
+	saveMember(c, models.Membership{OrganizationID: team.OrganizationID, TeamID: team.ID})
+}
+
+// DeleteTeamMember はチームへの所属を削除するハンドラーです。
+// チームのマネージャーは参加者の削除のみ、組織の管理者はすべての所属を削除できます
+func DeleteTeamMember(c *gin.Context) {
+	team, ok := authorizeTeam(c, true)
+	if !ok {
+		return
+	}
+	deleteMember(c, team.OrganizationID, team.ID)
+}
+
+// AcceptTeamMembership はチームへの自分の所属を承諾するハンドラーです。
+// 承諾するとチームのマネージャーが自分の診断結果を参照できるようになります
+func AcceptTeamMembership(c *gin.Context) {
+	team, ok := loadTeam(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	acceptMembership(c, team.OrganizationID, team.ID)
+}
+
+// respondMembers は条件に一致する所属をユーザーの情報とともに返します
+func respondMembers(c *gin.Context, filter storage.MembershipFilter) {
+	memberships, err := store.ListMemberships(filter)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	members := make([]memberResponse, 0, len(memberships))
+	for _, m := range memberships {
+		member := memberResponse{Membership: m}
+		user, err := store.GetUser(m.UserID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+			return
+		}
+		if user != nil {
+			member.Email, member.Name = user.Email, user.Name
+		}
+		members = append(members, member)
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// saveMember はリクエストのユーザーと役割で所属を保存します。チームに追加するユーザーは組織に所属している必要があります。
+// 組織の管理者でない場合は参加者以外の役割を与えることも、既存の役割を変えることもできません。
+// 他のユーザーを追加した所属は、本人が承諾するまで有効になりません
+func saveMember(c *gin.Context, membership models.Membership) {
+	var request memberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+
+	user, ok := resolveMember(c, request)
+	if !ok {
+		return
+	}
+	membership.UserID = user.ID
+	membership.Role = request.Role
+	if membership.Role == "" {
+		membership.Role = models.RoleParticipant
+	}
+	if !membership.ValidRole() {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_role", request.Role))
+		return
+	}
+
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return
+	}
+	if !p.IsOrgAdmin(membership.OrganizationID) {
+		current, err := findMembership(membership.OrganizationID, membership.TeamID, user.ID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+			return
+		}
+		if membership.Role != models.RoleParticipant || (current != nil && current.Role != models.RoleParticipant) {
+			abortForbidden(c)
+			return
+		}
+	}
+
+	// チームに追加できるのは組織に所属しているユーザーのみ
+	if membership.TeamID != "" {
+		orgMembership, err := findMembership(membership.OrganizationID, "", user.ID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+			return
+		}
+		if orgMembership == nil {
+			abortWithError(c, http.StatusBadRequest, CodeNotOrganizationMember, i18n.New("error.not_organization_member", user.Email))
+			return
+		}
+	}
+
+	// 他のユーザーの所属は本人が承諾するまで有効にしない
+	if user.ID == p.UserID {
+		now := time.Now().UTC()
+		membership.AcceptedAt = &now
+	}
+	if err := store.SaveMembership(&membership); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusOK, memberResponse{Membership: membership, Email: user.Email, Name: user.Name})
+}
+
+// acceptMembership は現在のユーザーの所属を承諾します。所属していない場合は 404 を返します
+func acceptMembership(c *gin.Context, organizationID, teamID string) {
+	user := currentUser(c)
+	membership, err := store.AcceptMembership(organizationID, teamID, user.ID, time.Now())
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.membership_not_found", user.ID))
+		return
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(http.StatusOK, memberResponse{Membership: *membership, Email: user.Email, Name: user.Name})
+}
+
+// deleteMember はパスのユーザーの所属を削除します。組織から外す場合はその組織のチームへの所属も削除します。
+// 組織の管理者でない場合は参加者のみを削除できます
+func deleteMember(c *gin.Context, organizationID, teamID string) {
+	userID := c.Param("userId")
+	current, err := findMembership(organizationID, teamID, userID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	if current == nil {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.membership_not_found", userID))
+		return
+	}
+
+	p, ok := principalOrAbort(c)
+	if !ok {
+		return
+	}
+	if !p.IsOrgAdmin(organizationID) && current.Role != models.RoleParticipant {
+		abortForbidden(c)
+		return
+	}
+
+	// 組織から外す場合は組織内のチームからも外す
+	memberships := []models.Membership{*current}
+	if teamID == "" {
+		var err error
+		memberships, err = store.ListMemberships(storage.MembershipFilter{OrganizationID: organizationID, UserID: userID})
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+			return
+		}
+	}
+	for _, m := range memberships {
+		if err := store.DeleteMembership(m.OrganizationID, m.TeamID, m.UserID); err != nil {
+			abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+			return
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// findMembership は所属を返します。所属していない場合は nil を返します
+func findMembership(organizationID, teamID, userID string) (*models.Membership, error) {
+	memberships, err := store.ListMemberships(storage.MembershipFilter{OrganizationID: organizationID, TeamID: teamID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memberships {
+		// TeamID が空の条件はチームへの所属にも一致するため、組織への所属を区別する
+		if m.TeamID == teamID {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveMember はリクエストの userId または email のユーザーを読み込みます。
+// 見つからない場合はエラーを記録して false を返します
+func resolveMember(c *gin.Context, request memberRequest) (*models.User, bool) {
+	var user *models.User
+	var err error
+	key := request.UserID
+	switch {
+	case request.UserID != "":
+		user, err = store.GetUser(request.UserID)
+	case request.Email != "":
+		key = request.Email
+		user, err = store.GetUserByEmail(request.Email)
+	default:
+		abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.member_required"))
+		return nil, false
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.user_not_found", key))
+		return nil, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	return user, true
+}
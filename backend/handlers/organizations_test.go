@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/models"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupOrganizationsRouter(t *testing.T) *gin.Engine {
+	setupStore(t)
+	setupAuth(t)
+	t.Cleanup(func() { SetSuperadmins(nil) })
+
+	r := setupAuthRouter()
+	r.GET("/api/results/:id/report.pdf", AuthorizeResult(), GetResultReport)
+	r.GET("/api/export", ExportResults)
+	orgs := r.Group("/api", RequireAuth())
+	orgs.POST("/organizations", CreateOrganization)
+	orgs.GET("/organizations", ListOrganizations)
+	orgs.GET("/organizations/:id", GetOrganization)
+	orgs.GET("/organizations/:id/teams", ListTeams)
+	orgs.POST("/organizations/:id/teams", CreateTeam)
+	orgs.GET("/organizations/:id/members", ListOrganizationMembers)
+	orgs.PUT("/organizations/:id/members", SaveOrganizationMember)
+	orgs.DELETE("/organizations/:id/members/:userId", DeleteOrganizationMember)
+	orgs.POST("/organizations/:id/accept", AcceptOrganizationMembership)
+	orgs.GET("/teams/:id", GetTeam)
+	orgs.GET("/teams/:id/members", ListTeamMembers)
+	orgs.PUT("/teams/:id/members", SaveTeamMember)
+	orgs.DELETE("/teams/:id/members/:userId", DeleteTeamMember)
+	orgs.POST("/teams/:id/accept", AcceptTeamMembership)
+	return r
+}
+
+// registerSuperadmin はユーザーを登録し、システム全体の管理者に設定してアクセストークンを返します
+func registerSuperadmin(t *testing.T, router *gin.Engine, email string) string {
+	t.Helper()
+	response := registerUser(t, router, email)
+	SetSuperadmins([]string{response.User.ID})
+	return response.AccessToken
+}
+
+// addMember は所属を追加し、追加されたユーザーとして承諾します。path は組織またはチームのパスです
+func addMember(t *testing.T, router *gin.Engine, path, body, token, memberToken string) {
+	t.Helper()
+	expectStatus(t, router, "PUT", path+"/members", body, token, http.StatusOK)
+	expectStatus(t, router, "POST", path+"/accept", "", memberToken, http.StatusOK)
+}
+
+// expectStatus はリクエストを送信し、ステータスコードを確認します
+func expectStatus(t *testing.T, router *gin.Engine, method, path, body, token string, expected int) []byte {
+	t.Helper()
+	w := doAuthJSON(router, method, path, body, token)
+	if w.Code != expected {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, expected, w.Code, w.Body.String())
+	}
+	return w.Body.Bytes()
+}
+
+func TestOrganizationAccessControl(t *testing.T) {
+	router := setupOrganizationsRouter(t)
+	root := registerSuperadmin(t, router, "root@example.com")
+	admin := registerUser(t, router, "admin@example.com")
+	manager := registerUser(t, router, "manager@example.com")
+	member := registerUser(t, router, "member@example.com")
+	outsider := registerUser(t, router, "outsider@example.com")
+
+	// 組織を作成できるのはシステム全体の管理者のみ
+	expectStatus(t, router, "POST", "/api/organizations", `{"name":"Acme"}`, admin.AccessToken, http.StatusForbidden)
+	var org models.Organization
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/organizations", `{"name":"Acme"}`, root, http.StatusCreated), &org)
+	orgPath := "/api/organizations/" + org.ID
+
+	// 承諾するまでは組織の管理者として操作できない
+	expectStatus(t, router, "PUT", orgPath+"/members", `{"email":"admin@example.com","role":"org_admin"}`, root, http.StatusOK)
+	expectStatus(t, router, "POST", orgPath+"/teams", `{"name":"Sales"}`, admin.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "POST", orgPath+"/accept", "", outsider.AccessToken, http.StatusNotFound)
+	expectStatus(t, router, "POST", orgPath+"/accept", "", admin.AccessToken, http.StatusOK)
+
+	// 組織の管理者がチームを作成し、組織のメンバーをチームに追加する
+	var sales, dev models.Team
+	json.Unmarshal(expectStatus(t, router, "POST", orgPath+"/teams", `{"name":"Sales"}`, admin.AccessToken, http.StatusCreated), &sales)
+	json.Unmarshal(expectStatus(t, router, "POST", orgPath+"/teams", `{"name":"Dev"}`, admin.AccessToken, http.StatusCreated), &dev)
+	addMember(t, router, orgPath, `{"email":"manager@example.com"}`, admin.AccessToken, manager.AccessToken)
+	addMember(t, router, orgPath, `{"email":"outsider@example.com"}`, admin.AccessToken, outsider.AccessToken)
+	addMember(t, router, "/api/teams/"+sales.ID, `{"email":"manager@example.com","role":"manager"}`, admin.AccessToken, manager.AccessToken)
+	addMember(t, router, "/api/teams/"+dev.ID, `{"email":"outsider@example.com"}`, admin.AccessToken, outsider.AccessToken)
+
+	// 組織に所属していないユーザーはマネージャーも組織の管理者もチームに追加できない
+	expectStatus(t, router, "PUT", "/api/teams/"+sales.ID+"/members", `{"userId":"`+member.User.ID+`"}`, manager.AccessToken, http.StatusBadRequest)
+	expectStatus(t, router, "PUT", "/api/teams/"+sales.ID+"/members", `{"email":"member@example.com"}`, admin.AccessToken, http.StatusBadRequest)
+	expectStatus(t, router, "PUT", "/api/organizations/"+org.ID+"/members", `{"email":"member@example.com"}`, admin.AccessToken, http.StatusOK)
+
+	// マネージャーは参加者を追加できるが、マネージャーは指名できない
+	expectStatus(t, router, "PUT", "/api/teams/"+sales.ID+"/members", `{"userId":"`+member.User.ID+`"}`, manager.AccessToken, http.StatusOK)
+	expectStatus(t, router, "PUT", "/api/teams/"+sales.ID+"/members", `{"userId":"`+member.User.ID+`","role":"manager"}`, manager.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "PUT", "/api/teams/"+dev.ID+"/members", `{"userId":"`+member.User.ID+`"}`, manager.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "PUT", "/api/teams/"+sales.ID+"/members", `{"email":"nobody@example.com"}`, manager.AccessToken, http.StatusNotFound)
+	expectStatus(t, router, "PUT", "/api/teams/"+sales.ID+"/members", `{"email":"member@example.com","role":"org_admin"}`, admin.AccessToken, http.StatusBadRequest)
+
+	var members []memberResponse
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/teams/"+sales.ID+"/members", "", manager.AccessToken, http.StatusOK), &members)
+	if len(members) != 2 || members[1].Email != "member@example.com" || members[1].Role != models.RoleParticipant || members[1].AcceptedAt != nil {
+		t.Errorf("Unexpected team members: %+v", members)
+	}
+	expectStatus(t, router, "GET", "/api/teams/"+sales.ID+"/members", "", member.AccessToken, http.StatusForbidden)
+
+	// 参加者の診断結果は、参加者が所属を承諾するまでマネージャーも組織の管理者も参照できない
+	var result calculateResponse
+	json.Unmarshal(expectStatus(t, router, "POST", "/api/calculate", `{"responses":[{"questionId":1,"score":5}]}`, member.AccessToken, http.StatusOK), &result)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", manager.AccessToken, http.StatusNotFound)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", admin.AccessToken, http.StatusNotFound)
+	var listed []models.Assessment
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results?teamId="+sales.ID, "", manager.AccessToken, http.StatusOK), &listed)
+	if len(listed) != 0 {
+		t.Errorf("Expected no results before the member accepts, got %+v", listed)
+	}
+	expectStatus(t, router, "GET", "/api/teams/"+sales.ID, "", member.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "POST", orgPath+"/accept", "", member.AccessToken, http.StatusOK)
+	expectStatus(t, router, "POST", "/api/teams/"+sales.ID+"/accept", "", member.AccessToken, http.StatusOK)
+	expectStatus(t, router, "GET", "/api/teams/"+sales.ID, "", member.AccessToken, http.StatusOK)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{name: "本人", token: member.AccessToken, expected: http.StatusOK},
+		{name: "チームのマネージャー", token: manager.AccessToken, expected: http.StatusOK},
+		{name: "組織の管理者", token: admin.AccessToken, expected: http.StatusOK},
+		{name: "システム全体の管理者", token: root, expected: http.StatusOK},
+		{name: "他のチームのメンバー", token: outsider.AccessToken, expected: http.StatusNotFound},
+		{name: "匿名", expected: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, router, "GET", "/api/results/"+result.ID, "", tt.token, tt.expected)
+			expectStatus(t, router, "GET", "/api/results/"+result.ID+"/report.pdf", "", tt.token, tt.expected)
+		})
+	}
+
+	// チームと組織の単位での一覧
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results?teamId="+sales.ID, "", manager.AccessToken, http.StatusOK), &listed)
+	if len(listed) != 1 || listed[0].ID != result.ID {
+		t.Errorf("Expected the team member's result, got %+v", listed)
+	}
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/results?organizationId="+org.ID, "", admin.AccessToken, http.StatusOK), &listed)
+	if len(listed) != 1 {
+		t.Errorf("Expected 1 result in the organization, got %d", len(listed))
+	}
+	expectStatus(t, router, "GET", "/api/results?teamId="+sales.ID, "", outsider.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "GET", "/api/results?organizationId="+org.ID, "", manager.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "GET", "/api/results?teamId="+sales.ID, "", "", http.StatusUnauthorized)
+	expectStatus(t, router, "GET", "/api/results?teamId=missing", "", manager.AccessToken, http.StatusNotFound)
+	expectStatus(t, router, "GET", "/api/export?format=jsonl&teamId="+sales.ID, "", outsider.AccessToken, http.StatusForbidden)
+
+	// 組織の一覧は所属している組織のみ
+	var organizations []models.Organization
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/organizations", "", member.AccessToken, http.StatusOK), &organizations)
+	if len(organizations) != 1 || organizations[0].ID != org.ID {
+		t.Errorf("Unexpected organizations: %+v", organizations)
+	}
+	expectStatus(t, router, "GET", "/api/organizations/"+org.ID+"/members", "", manager.AccessToken, http.StatusForbidden)
+
+	// チームから外れるとマネージャーは参照できなくなる
+	expectStatus(t, router, "DELETE", "/api/teams/"+sales.ID+"/members/"+manager.User.ID, "", manager.AccessToken, http.StatusForbidden)
+	expectStatus(t, router, "DELETE", "/api/teams/"+sales.ID+"/members/"+member.User.ID, "", manager.AccessToken, http.StatusNoContent)
+	expectStatus(t, router, "DELETE", "/api/teams/"+sales.ID+"/members/"+member.User.ID, "", manager.AccessToken, http.StatusNotFound)
+	expectStatus(t, router, "GET", "/api/results/"+result.ID, "", manager.AccessToken, http.StatusNotFound)
+
+	// 組織から外すとチームからも外れる
+	expectStatus(t, router, "DELETE", "/api/organizations/"+org.ID+"/members/"+outsider.User.ID, "", admin.AccessToken, http.StatusNoContent)
+	var devMembers []memberResponse
+	json.Unmarshal(expectStatus(t, router, "GET", "/api/teams/"+dev.ID+"/members", "", admin.AccessToken, http.StatusOK), &devMembers)
+	if len(devMembers) != 0 {
+		t.Errorf("Expected the removed user to leave the team, got %+v", devMembers)
+	}
+}
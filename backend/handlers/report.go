@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"hpcs/report"
+	"hpcs/scoring"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportFont は PDF レポートと PNG のチャートに使う TrueType フォントのパスです（空の場合は英語で作成します）
+var reportFont string
+
+// SetReportFont は PDF レポートと PNG のチャートに使うフォントを設定します
+func SetReportFont(path string) {
+	reportFont = path
+}
+
+// GetResultReport は保存済みの診断結果の PDF レポートを返すハンドラーです
+func GetResultReport(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	assessment, ok := loadAssessment(c)
+	if !ok {
+		return
+	}
+	inst, err := lookupInstrument(assessment.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	// 採点時の規準値が設定から外れている場合は規準参照得点を省略する
+	normSet, _ := resolveNormSet(inst, assessment.NormSet)
+
+	var buf bytes.Buffer
+	err = report.Render(&buf, assessment, inst, scoring.New(inst).Norms(normSet, assessment.Result, assessment.Demographics), report.Options{
+		Lang:     requestLang(c),
+		FontPath: reportFont,
+	})
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="hpcs-report-%s.pdf"`, assessment.ID))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetResultReport(t *testing.T) {
+	setupStore(t)
+	setupNorms(t)
+	router := setupResultsRouter()
+
+	w := doJSON(router, "POST", "/api/calculate", `{"responses":[{"questionId":1,"score":5},{"questionId":5,"score":2}]}`)
+	var created struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/results/"+created.ID+"/report.pdf", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("Expected Content-Type application/pdf, got %s", got)
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Error("Expected PDF body")
+	}
+
+	// 存在しない結果
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/results/unknown/report.pdf", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGetResultReportStorageDisabled(t *testing.T) {
+	router := setupResultsRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/results/abc/report.pdf", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// store は診断結果の保存先です（nil の場合は保存しません）
+var store storage.Store
+
+// SetStore はハンドラーが使用する保存先を設定します
+func SetStore(s storage.Store) {
+	store = s
+}
+
+// errStorageDisabled は保存先が設定されていない場合のエラーです
+var errStorageDisabled = i18n.New("error.storage_disabled")
+
+// GetResult は保存済みの診断結果を返すハンドラーです
+func GetResult(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	assessment, ok := loadAssessment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, assessment)
+}
+
+// loadAssessment はパスの ID の診断結果を読み込みます。AuthorizeResult で認可済みの場合はその診断結果を返します。
+// 見つからない場合はエラーを記録して false を返します
+func loadAssessment(c *gin.Context) (*models.Assessment, bool) {
+	if assessment, ok := c.Get(assessmentKey); ok {
+		return assessment.(*models.Assessment), true
+	}
+	return loadAssessmentByID(c, c.Param("id"))
+}
+
+// loadAssessmentByID は現在のユーザーが参照できる診断結果を読み込みます。
+// 見つからない場合と参照する権限がない場合は 404 を記録して false を返します
+func loadAssessmentByID(c *gin.Context, id string) (*models.Assessment, bool) {
+	assessment, err := store.GetAssessment(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.result_not_found", id))
+		return nil, false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	allowed, err := canReadResult(c, assessment)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return nil, false
+	}
+	if !allowed {
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.result_not_found", id))
+		return nil, false
+	}
+	return assessment, true
+}
+
+// ListResults は保存済みの診断結果の一覧を返すハンドラーです
+func ListResults(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	filter := storage.AssessmentFilter{InstrumentID: c.Query("instrumentId")}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter, i18n.New("error.invalid_limit", limit))
+			return
+		}
+		filter.Limit = n
+	}
+
+	filter, ok := resultScope(c, filter)
+	if !ok {
+		return
+	}
+
+	assessments, err := store.ListAssessments(filter)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assessments)
+}
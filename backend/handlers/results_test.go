@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/models"
+	"hpcs/storage"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupStore はテスト用の一時データベースを保存先に設定します
+func setupStore(t *testing.T) *storage.BoltStore {
+	t.Helper()
+	s, err := storage.OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	SetStore(s)
+	t.Cleanup(func() {
+		SetStore(nil)
+		s.Close()
+	})
+	return s
+}
+
+func setupResultsRouter() *gin.Engine {
+	r := setupRouter()
+	r.GET("/api/results", ListResults)
+	r.GET("/api/results/:id", GetResult)
+	r.GET("/api/results/:id/report.pdf", GetResultReport)
+	r.GET("/api/results/:id/chart.svg", GetResultChartSVG)
+	r.GET("/api/results/:id/chart.png", GetResultChartPNG)
+	return r
+}
+
+func TestCalculateScoreStoresResult(t *testing.T) {
+	setupStore(t)
+	router := setupResultsRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/calculate",
+		strings.NewReader(`{"responses":[`+neuroticism4+`,`+extraversion2+`]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+		models.Result
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatal("Expected response to contain the stored result ID")
+	}
+
+	// 保存した結果の取得
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/results/"+created.ID, nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var assessment models.Assessment
+	if err := json.Unmarshal(w.Body.Bytes(), &assessment); err != nil {
+		t.Fatalf("Failed to unmarshal assessment: %v", err)
+	}
+	if assessment.InstrumentID != "hpcs-74" || assessment.InstrumentVersion != "1.2.0" {
+		t.Errorf("Unexpected instrument: %s %s", assessment.InstrumentID, assessment.InstrumentVersion)
+	}
+	if len(assessment.Responses) != 9 || *assessment.Result.Neuroticism != *created.Neuroticism {
+		t.Errorf("Unexpected assessment: %+v", assessment)
+	}
+	if assessment.CreatedAt.IsZero() {
+		t.Error("Expected createdAt to be set")
+	}
+
+	// 一覧の取得
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/results?instrumentId=hpcs-74", nil)
+	router.ServeHTTP(w, req)
+
+	var list []models.Assessment
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal list: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != created.ID {
+		t.Errorf("Expected list to contain the stored result, got %+v", list)
+	}
+}
+
+func TestResultsErrors(t *testing.T) {
+	router := setupResultsRouter()
+
+	// 保存先が未設定
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/results", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d without storage, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	setupStore(t)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "存在しない結果", path: "/api/results/missing", expectedStatus: http.StatusNotFound},
+		{name: "不正なlimit", path: "/api/results?limit=abc", expectedStatus: http.StatusBadRequest},
+		{name: "空の一覧", path: "/api/results", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
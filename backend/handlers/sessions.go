@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/scoring"
+	"hpcs/storage"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errSessionCompleted は完了済みのセッションを変更しようとした場合のエラーです
+var errSessionCompleted = i18n.New("error.session_completed")
+
+// sessionProgress はセッションの進捗を表すレスポンスです
+type sessionProgress struct {
+	*models.Session
+	Answered int `json:"answered"`
+	Total    int `json:"total"`
+}
+
+// newSessionProgress はセッションの進捗を計算します
+func newSessionProgress(session *models.Session) (sessionProgress, error) {
+	inst, err := lookupInstrument(session.InstrumentID)
+	if err != nil {
+		return sessionProgress{}, err
+	}
+	return sessionProgress{
+		Session:  session,
+		Answered: len(session.Responses),
+		Total:    inst.Bank().Len(),
+	}, nil
+}
+
+// respondSessionError はセッション操作のエラーをレスポンスに変換します
+func respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		abortWithError(c, http.StatusNotFound, CodeNotFound, i18n.New("error.session_not_found", c.Param("id")))
+	case errors.Is(err, errSessionCompleted):
+		abortWithError(c, http.StatusConflict, CodeSessionCompleted, err)
+	default:
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+	}
+}
+
+// getSession はパスの ID のセッションを読み込みます。他のユーザーのセッションの場合は storage.ErrNotFound を返します
+func getSession(c *gin.Context) (*models.Session, error) {
+	session, err := store.GetSession(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(c, session.OwnerID) {
+		return nil, storage.ErrNotFound
+	}
+	return session, nil
+}
+
+// respondSessionProgress はセッションの進捗を返します
+func respondSessionProgress(c *gin.Context, status int, session *models.Session) {
+	progress, err := newSessionProgress(session)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	c.JSON(status, progress)
+}
+
+// CreateSession は新しい診断セッションを開始するハンドラーです
+func CreateSession(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	var request struct {
+		InstrumentID string               `json:"instrumentId"`
+		Demographics *models.Demographics `json:"demographics"`
+		NormSet      string               `json:"normSet"`
+		// InvitationToken は招待のトークンです（指定した場合は実施の検査と規準値を使います）
+		InvitationToken string `json:"invitationToken"`
+	}
+	// ボディは省略可能（省略時はデフォルトの検査）
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+			return
+		}
+	}
+
+	var campaign *models.Campaign
+	if request.InvitationToken != "" {
+		var ok bool
+		if _, campaign, ok = loadInvitation(c, request.InvitationToken); !ok {
+			return
+		}
+		if request.InstrumentID != "" && request.InstrumentID != campaign.InstrumentID {
+			abortWithError(c, http.StatusBadRequest, CodeInvalidParameter,
+				i18n.New("error.invalid_parameter", "instrumentId", request.InstrumentID))
+			return
+		}
+		request.InstrumentID, request.NormSet = campaign.InstrumentID, campaign.NormSet
+	}
+
+	inst, err := lookupInstrument(request.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeUnknownInstrument, err)
+		return
+	}
+
+	if _, err := resolveNormSet(inst, request.NormSet); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidNormSet, err)
+		return
+	}
+
+	session := &models.Session{
+		InstrumentID: inst.ID,
+		Status:       models.SessionInProgress,
+		Responses:    []models.Response{},
+		Demographics: request.Demographics,
+		NormSet:      request.NormSet,
+		OwnerID:      currentUserID(c),
+	}
+	if campaign != nil {
+		// 同時に使われた場合に備え、使用回数はセッションの作成の直前に確定する
+		if _, err := store.RedeemInvitation(request.InvitationToken, time.Now()); err != nil {
+			respondInvitationError(c, err)
+			return
+		}
+		session.CampaignID = campaign.ID
+		session.InvitationToken = request.InvitationToken
+	}
+	if err := store.CreateSession(session); err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	respondSessionProgress(c, http.StatusCreated, session)
+}
+
+// GetSession はセッションの進捗と回答済みの内容を返すハンドラーです
+func GetSession(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	respondSessionProgress(c, http.StatusOK, session)
+}
+
+// SaveSessionResponses はセッションに回答を追加または上書きするハンドラーです
+func SaveSessionResponses(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	var request struct {
+		Responses []models.Response `json:"responses"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		abortWithError(c, http.StatusBadRequest, CodeInvalidJSON, i18n.New("error.invalid_json", err.Error()))
+		return
+	}
+
+	current, err := getSession(c)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	inst, err := lookupInstrument(current.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	// バリデーション
+	if err := scoring.New(inst).Validate(request.Responses); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	session, err := store.UpdateSession(c.Param("id"), func(s *models.Session) error {
+		if s.Status == models.SessionCompleted {
+			return errSessionCompleted
+		}
+		s.Upsert(request.Responses)
+		return nil
+	})
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	respondSessionProgress(c, http.StatusOK, session)
+}
+
+// CompleteSession はセッションの回答を採点し、結果を確定するハンドラーです。
+// 完了済みのセッションに対しては確定済みの結果を返します
+func CompleteSession(c *gin.Context) {
+	if store == nil {
+		abortWithStorageDisabled(c)
+		return
+	}
+
+	current, err := getSession(c)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	inst, err := lookupInstrument(current.InstrumentID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+
+	// 完了済みかどうかの確認から結果の確定までを、セッションの1つのトランザクションで行う
+	var scoreErr error
+	session, err := store.CompleteSession(current.ID, func(s *models.Session) (*models.Assessment, error) {
+		if s.Status == models.SessionCompleted {
+			return nil, nil
+		}
+		normSet, err := resolveNormSet(inst, s.NormSet)
+		if err != nil {
+			return nil, err
+		}
+
+		// スコア計算（回答時間はセッション開始からの経過時間）
+		response, assessment, err := scoreSubmission(scoring.New(inst), scoring.Submission{
+			Responses:    s.Responses,
+			Demographics: s.Demographics,
+			NormSet:      normSet,
+			Duration:     time.Since(s.CreatedAt),
+		}, resultOrigin{OwnerID: s.OwnerID, CampaignID: s.CampaignID})
+		if err != nil {
+			scoreErr = err
+			return nil, err
+		}
+		if s.Outcome, err = json.Marshal(response); err != nil {
+			return nil, err
+		}
+
+		completedAt := time.Now().UTC()
+		s.Status = models.SessionCompleted
+		s.CompletedAt = &completedAt
+		return assessment, nil
+	})
+	switch {
+	case scoreErr != nil:
+		abortWithScoringError(c, scoreErr)
+		return
+	case err != nil:
+		respondSessionError(c, err)
+		return
+	}
+
+	response, err := sessionOutcome(session)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	response.localize(inst, instrumentLang(c, inst))
+	c.JSON(http.StatusOK, response)
+}
+
+// sessionOutcome は完了したセッションの確定済みの結果を返します。
+// 確定した採点結果を保存していないセッションの場合は、保存済みの診断結果の得点のみを返します
+func sessionOutcome(session *models.Session) (calculateResponse, error) {
+	var response calculateResponse
+	if len(session.Outcome) > 0 {
+		if err := json.Unmarshal(session.Outcome, &response); err != nil {
+			return calculateResponse{}, err
+		}
+	} else {
+		assessment, err := store.GetAssessment(session.AssessmentID)
+		if err != nil {
+			return calculateResponse{}, err
+		}
+		response.Result = assessment.Result
+	}
+	response.ID = session.AssessmentID
+	return response, nil
+}
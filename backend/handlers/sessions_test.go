@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"hpcs/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupSessionsRouter() *gin.Engine {
+	r := setupResultsRouter()
+	r.POST("/api/sessions", CreateSession)
+	r.GET("/api/sessions/:id", GetSession)
+	r.PUT("/api/sessions/:id/responses", SaveSessionResponses)
+	r.POST("/api/sessions/:id/complete", CompleteSession)
+	return r
+}
+
+// doJSON はJSONボディ付きのリクエストを送信します
+func doJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+type sessionResponse struct {
+	models.Session
+	Answered int `json:"answered"`
+	Total    int `json:"total"`
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	setupStore(t)
+	router := setupSessionsRouter()
+
+	// セッションの開始（ボディ省略時はデフォルトの検査）
+	w := doJSON(router, "POST", "/api/sessions", "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var session sessionResponse
+	json.Unmarshal(w.Body.Bytes(), &session)
+	if session.ID == "" || session.Status != models.SessionInProgress || session.Total != 74 {
+		t.Fatalf("Unexpected session: %+v", session)
+	}
+	path := "/api/sessions/" + session.ID
+
+	// 回答の保存と上書き
+	w = doJSON(router, "PUT", path+"/responses", `{"responses":[{"questionId":1,"score":2},{"questionId":2,"score":5},{"questionId":29,"score":2},{"questionId":30,"score":2}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	w = doJSON(router, "PUT", path+"/responses", `{"responses":[{"questionId":1,"score":5}]}`)
+	json.Unmarshal(w.Body.Bytes(), &session)
+	if session.Answered != 4 {
+		t.Errorf("Expected 4 answered questions after upsert, got %d", session.Answered)
+	}
+
+	// 不正な回答は保存されない
+	w = doJSON(router, "PUT", path+"/responses", `{"responses":[{"questionId":999,"score":3}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid question, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// 進捗の取得
+	w = doJSON(router, "GET", path, "")
+	json.Unmarshal(w.Body.Bytes(), &session)
+	if session.Answered != 4 || session.Responses[0].Score != 5 {
+		t.Errorf("Unexpected progress: %+v", session)
+	}
+
+	// 完了と結果の確定
+	w = doJSON(router, "POST", path+"/complete", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var completed struct {
+		ID string `json:"id"`
+		models.Result
+	}
+	json.Unmarshal(w.Body.Bytes(), &completed)
+	if score, _ := completed.Get("neuroticism"); completed.ID == "" || !almostEqual(score, 4.5, 0.01) {
+		t.Errorf("Unexpected completion result: %+v", completed)
+	}
+
+	// 完了後は確定した結果（規準参照得点と品質指標を含む）がそのまま返り、回答は変更できない
+	first := w.Body.String()
+	w = doJSON(router, "POST", path+"/complete", "")
+	var again struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &again)
+	if again.ID != completed.ID {
+		t.Errorf("Expected frozen result %s, got %s", completed.ID, again.ID)
+	}
+	if w.Body.String() != first {
+		t.Errorf("Expected the frozen response %s, got %s", first, w.Body.String())
+	}
+	w = doJSON(router, "PUT", path+"/responses", `{"responses":[{"questionId":2,"score":3}]}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d after completion, got %d", http.StatusConflict, w.Code)
+	}
+
+	w = doJSON(router, "GET", path, "")
+	json.Unmarshal(w.Body.Bytes(), &session)
+	if session.Status != models.SessionCompleted || session.AssessmentID != completed.ID || session.CompletedAt == nil {
+		t.Errorf("Unexpected completed session: %+v", session)
+	}
+
+	// 確定した結果は結果APIから取得できる
+	w = doJSON(router, "GET", "/api/results/"+completed.ID, "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected stored result, got status %d", w.Code)
+	}
+}
+
+func TestSessionErrors(t *testing.T) {
+	setupStore(t)
+	router := setupSessionsRouter()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "存在しない検査", method: "POST", path: "/api/sessions", body: `{"instrumentId":"unknown"}`, expectedStatus: http.StatusBadRequest},
+		{name: "存在しないセッション", method: "GET", path: "/api/sessions/missing", expectedStatus: http.StatusNotFound},
+		{name: "存在しないセッションへの回答", method: "PUT", path: "/api/sessions/missing/responses", body: `{"responses":[]}`, expectedStatus: http.StatusNotFound},
+		{name: "存在しないセッションの完了", method: "POST", path: "/api/sessions/missing/complete", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doJSON(router, tt.method, tt.path, tt.body)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
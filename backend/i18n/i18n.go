@@ -0,0 +1,149 @@
+// Package i18n は API のメッセージとラベルの翻訳カタログです
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 対応する言語
+const (
+	Japanese = "ja"
+	English  = "en"
+)
+
+// Fallback はカタログに訳がない場合や言語が指定されない場合に使う言語です
+const Fallback = English
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// catalog は言語ごとのメッセージの書式です
+var catalog = mustLoadCatalog()
+
+// mustLoadCatalog は同梱のカタログを読み込みます
+func mustLoadCatalog() map[string]map[string]string {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		panic("failed to load i18n catalog: " + err.Error())
+	}
+	catalog := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := locales.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic("failed to load i18n catalog: " + err.Error())
+		}
+		var messages map[string]string
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("failed to load i18n catalog %s: %v", entry.Name(), err))
+		}
+		catalog[strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))] = messages
+	}
+	return catalog
+}
+
+// Supported は言語がカタログに含まれるかどうかを返します
+func Supported(lang string) bool {
+	_, ok := catalog[lang]
+	return ok
+}
+
+// Negotiate は lang パラメータと Accept-Language ヘッダーから応答する言語を決めます。
+// lang パラメータを優先し、どちらにも対応する言語がない場合は空文字列を返します
+func Negotiate(query, acceptLanguage string) string {
+	if lang := baseLanguage(query); Supported(lang) {
+		return lang
+	}
+
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if lang := baseLanguage(tag); q > 0 && Supported(lang) {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// baseLanguage は言語タグから主言語を取り出します（例: ja-JP → ja）
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	return strings.ToLower(base)
+}
+
+// Has はキーに対応するメッセージがカタログにあるかどうかを返します
+func Has(key string) bool {
+	_, ok := catalog[Fallback][key]
+	return ok
+}
+
+// T はキーに対応するメッセージを指定した言語で書式化します。
+// 訳がない場合は Fallback の言語を、それもない場合はキーをそのまま使います
+func T(lang, key string, args ...any) string {
+	format, ok := catalog[lang][key]
+	if !ok {
+		format, ok = catalog[Fallback][key]
+	}
+	if !ok {
+		return key
+	}
+	for i, arg := range args {
+		if m, ok := arg.(*Message); ok {
+			args[i] = m.In(lang)
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Message は翻訳可能なメッセージです。error としては Fallback の言語で表示されます
+type Message struct {
+	Key  string
+	Args []any
+}
+
+// New はメッセージを作成します。引数に Message を含めると同じ言語で翻訳されます
+func New(key string, args ...any) *Message {
+	return &Message{Key: key, Args: args}
+}
+
+// Error は Fallback の言語のメッセージを返します
+func (m *Message) Error() string {
+	return m.In(Fallback)
+}
+
+// In は指定した言語のメッセージを返します
+func (m *Message) In(lang string) string {
+	args := make([]any, len(m.Args))
+	copy(args, m.Args)
+	return T(lang, m.Key, args...)
+}
+
+// Localize はエラーが Message の場合は指定した言語で、それ以外の場合はそのままのメッセージを返します
+func Localize(err error, lang string) string {
+	if m, ok := err.(*Message); ok {
+		return m.In(lang)
+	}
+	return err.Error()
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "指定なし", want: ""},
+		{name: "langパラメータ", query: "en", acceptLanguage: "ja", want: English},
+		{name: "未対応のlangパラメータ", query: "fr", acceptLanguage: "ja-JP", want: Japanese},
+		{name: "地域付きの言語タグ", acceptLanguage: "ja-JP", want: Japanese},
+		{name: "品質値の高い言語を優先", acceptLanguage: "ja;q=0.5, en-US;q=0.9", want: English},
+		{name: "未対応の言語を読み飛ばす", acceptLanguage: "fr-FR, de;q=0.9, ja;q=0.1", want: Japanese},
+		{name: "品質値0は除外", acceptLanguage: "en;q=0, *", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.query, tt.acceptLanguage); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := New("validation.unknown_question", 999)
+
+	if got := m.Error(); got != "invalid question ID: 999" {
+		t.Errorf("Unexpected English message: %s", got)
+	}
+	if got := m.In(Japanese); got != "存在しない質問IDです: 999" {
+		t.Errorf("Unexpected Japanese message: %s", got)
+	}
+	// 未対応の言語は英語になる
+	if got := m.In("fr"); got != m.Error() {
+		t.Errorf("Expected fallback message, got %s", got)
+	}
+
+	// 入れ子のメッセージも同じ言語で翻訳される
+	nested := New("validation.respondent", 2, m)
+	if got := nested.In(Japanese); got != "回答者 2: 存在しない質問IDです: 999" {
+		t.Errorf("Unexpected nested message: %s", got)
+	}
+	if got := nested.Error(); got != "respondent 2: invalid question ID: 999" {
+		t.Errorf("Unexpected nested message: %s", got)
+	}
+
+	if got := Localize(errors.New("boom"), Japanese); got != "boom" {
+		t.Errorf("Expected untranslated error, got %s", got)
+	}
+	if got := T(Japanese, "no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected key for missing message, got %s", got)
+	}
+}
+
+func TestCatalogKeys(t *testing.T) {
+	// すべての言語が同じキーを持つ
+	for lang, messages := range catalog {
+		for key := range catalog[Fallback] {
+			if _, ok := messages[key]; !ok {
+				t.Errorf("%s: missing message %s", lang, key)
+			}
+		}
+		for key := range messages {
+			if _, ok := catalog[Fallback][key]; !ok {
+				t.Errorf("%s: unknown message %s", lang, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package interpret
+
+import (
+	"hpcs/analytics"
+	"hpcs/i18n"
+	"hpcs/models"
+	"math"
+)
+
+// 2人の水準の組み合わせ
+const (
+	PatternBothHigh = "both_high"
+	PatternBothLow  = "both_low"
+	// PatternContrast は一方が高く、もう一方が低いことを表します
+	PatternContrast = "contrast"
+)
+
+// 2人の組み合わせの注記の種類
+const (
+	// NoteComplement は互いに補い合いやすい組み合わせです
+	NoteComplement = "complement"
+	// NoteClash は摩擦が生じやすい組み合わせです
+	NoteClash = "clash"
+)
+
+// minCorrelationDimensions はプロファイル相関を計算する最小の次元数です
+const minCorrelationDimensions = 3
+
+// DimensionDifference は1つの次元の2人のスコアの差です。どちらかのスコアがない場合、差は nil になります
+type DimensionDifference struct {
+	Dimension string   `json:"dimension"`
+	A         *float64 `json:"a"`
+	B         *float64 `json:"b"`
+	// Difference は B から A を引いた差です
+	Difference *float64 `json:"difference"`
+	LevelA     string   `json:"levelA,omitempty"`
+	LevelB     string   `json:"levelB,omitempty"`
+}
+
+// Note は2人の組み合わせについての注記です
+type Note struct {
+	Dimension string `json:"dimension"`
+	Pattern   string `json:"pattern"`
+	Kind      string `json:"kind"`
+	Text      string `json:"text"`
+}
+
+// Comparison は2人の診断結果の比較です
+type Comparison struct {
+	Dimensions []DimensionDifference `json:"dimensions"`
+	// Distance は両方のスコアがある次元のユークリッド距離です
+	Distance *float64 `json:"distance"`
+	// Similarity は距離を尺度上の最大の距離で割り、1 から引いた 0〜1 の類似度です
+	Similarity *float64 `json:"similarity"`
+	// Correlation は次元のスコアを並べたプロファイルの相関です（3次元以上で、どちらのスコアもすべて同じでない場合のみ）
+	Correlation *float64 `json:"correlation"`
+	Notes       []Note   `json:"notes"`
+}
+
+// pairing は注記のある次元の水準の組み合わせです
+type pairing struct {
+	Dimension string
+	Pattern   string
+	Kind      string
+}
+
+// pairings は注記を持つ組み合わせです（定義順に評価します）
+var pairings = []pairing{
+	{Dimension: models.Neuroticism, Pattern: PatternBothHigh, Kind: NoteClash},
+	{Dimension: models.Neuroticism, Pattern: PatternContrast, Kind: NoteComplement},
+	{Dimension: models.Extraversion, Pattern: PatternBothHigh, Kind: NoteClash},
+	{Dimension: models.Extraversion, Pattern: PatternContrast, Kind: NoteComplement},
+	{Dimension: models.Conscientiousness, Pattern: PatternBothHigh, Kind: NoteComplement},
+	{Dimension: models.Conscientiousness, Pattern: PatternBothLow, Kind: NoteClash},
+	{Dimension: models.Conscientiousness, Pattern: PatternContrast, Kind: NoteClash},
+	{Dimension: models.Agreeableness, Pattern: PatternBothHigh, Kind: NoteComplement},
+	{Dimension: models.Agreeableness, Pattern: PatternBothLow, Kind: NoteClash},
+	{Dimension: models.Agreeableness, Pattern: PatternContrast, Kind: NoteComplement},
+	{Dimension: models.Openness, Pattern: PatternBothHigh, Kind: NoteComplement},
+	{Dimension: models.Openness, Pattern: PatternContrast, Kind: NoteClash},
+}
+
+// Compare は2人の診断結果を比較し、次元ごとの差、距離と相関、組み合わせの注記を指定した言語で返します。
+// labelA と labelB は注記の文中で2人を指す名前です。水準は尺度上の素点の位置で判定します
+func Compare(inst *models.Instrument, a, b models.Result, labelA, labelB, lang string) *Comparison {
+	comparison := &Comparison{
+		Dimensions: []DimensionDifference{},
+		Notes:      []Note{},
+	}
+
+	var xs, ys []float64
+	patterns := make(map[string]string)
+	higher := make(map[string]bool)
+	for _, dimension := range inst.DimensionIDs() {
+		d := DimensionDifference{Dimension: dimension}
+		x, okA := a.Get(dimension)
+		y, okB := b.Get(dimension)
+		if okA {
+			d.A = models.Float64(x)
+			d.LevelA = rawLevel(inst, x)
+		}
+		if okB {
+			d.B = models.Float64(y)
+			d.LevelB = rawLevel(inst, y)
+		}
+		if okA && okB {
+			d.Difference = analytics.Round3(y - x)
+			xs, ys = append(xs, x), append(ys, y)
+			patterns[dimension] = pattern(d.LevelA, d.LevelB)
+			higher[dimension] = x >= y
+		}
+		comparison.Dimensions = append(comparison.Dimensions, d)
+	}
+
+	if len(xs) > 0 {
+		var ss float64
+		for i := range xs {
+			ss += (xs[i] - ys[i]) * (xs[i] - ys[i])
+		}
+		distance := math.Sqrt(ss)
+		comparison.Distance = analytics.Round3(distance)
+		if max := float64(inst.Scale.Max-inst.Scale.Min) * math.Sqrt(float64(len(xs))); max > 0 {
+			comparison.Similarity = analytics.Round3(1 - distance/max)
+		}
+	}
+	if len(xs) >= minCorrelationDimensions {
+		comparison.Correlation = analytics.Round3(analytics.Correlation(xs, ys))
+	}
+
+	for _, p := range pairings {
+		if patterns[p.Dimension] != p.Pattern {
+			continue
+		}
+		// 差のある組み合わせでは、スコアの高い方を先に示す
+		first, second := labelA, labelB
+		if p.Pattern == PatternContrast && !higher[p.Dimension] {
+			first, second = labelB, labelA
+		}
+		comparison.Notes = append(comparison.Notes, Note{
+			Dimension: p.Dimension,
+			Pattern:   p.Pattern,
+			Kind:      p.Kind,
+			Text:      i18n.T(lang, "comparison."+p.Dimension+"."+p.Pattern, first, second),
+		})
+	}
+
+	return comparison
+}
+
+// pattern は2人の水準の組み合わせを返します。注記の対象でない場合は空文字列を返します
+func pattern(a, b string) string {
+	switch {
+	case a == High && b == High:
+		return PatternBothHigh
+	case a == Low && b == Low:
+		return PatternBothLow
+	case (a == High && b == Low) || (a == Low && b == High):
+		return PatternContrast
+	}
+	return ""
+}
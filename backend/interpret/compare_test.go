@@ -0,0 +1,101 @@
+package interpret
+
+import (
+	"hpcs/models"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	inst := testInstrument(t)
+	a := models.Result{
+		Neuroticism:       models.Float64(1.5),
+		Extraversion:      models.Float64(4.5),
+		Conscientiousness: models.Float64(4.0),
+		Agreeableness:     models.Float64(3.0),
+		Openness:          nil,
+	}
+	b := models.Result{
+		Neuroticism:       models.Float64(4.5),
+		Extraversion:      models.Float64(1.5),
+		Conscientiousness: models.Float64(4.5),
+		Agreeableness:     models.Float64(3.0),
+		Openness:          models.Float64(5.0),
+	}
+
+	comparison := Compare(inst, a, b, "Mentor", "Mentee", "en")
+
+	// custom を含むすべての次元を定義順に返す
+	if len(comparison.Dimensions) != 6 {
+		t.Fatalf("Expected 6 dimensions, got %d", len(comparison.Dimensions))
+	}
+	n := comparison.Dimensions[0]
+	if n.Difference == nil || *n.Difference != 3 || n.LevelA != Low || n.LevelB != High {
+		t.Errorf("Unexpected neuroticism difference: %+v", n)
+	}
+	if o := comparison.Dimensions[4]; o.A != nil || o.Difference != nil || o.LevelB != High {
+		t.Errorf("Expected no difference for openness: %+v", o)
+	}
+
+	// 両方のスコアがある4次元の距離: sqrt(9 + 9 + 0.25 + 0)
+	distance := math.Sqrt(18.25)
+	if comparison.Distance == nil || math.Abs(*comparison.Distance-distance) > 0.001 {
+		t.Errorf("Expected distance %.3f, got %v", distance, comparison.Distance)
+	}
+	if comparison.Similarity == nil || math.Abs(*comparison.Similarity-(1-distance/8)) > 0.001 {
+		t.Errorf("Expected similarity %.3f, got %v", 1-distance/8, comparison.Similarity)
+	}
+	if comparison.Correlation == nil || *comparison.Correlation >= 0 {
+		t.Errorf("Expected a negative profile correlation, got %v", comparison.Correlation)
+	}
+
+	tests := []struct {
+		dimension string
+		pattern   string
+		kind      string
+		prefix    string
+	}{
+		{dimension: models.Neuroticism, pattern: PatternContrast, kind: NoteComplement, prefix: "Mentee tends to feel stress more strongly than Mentor"},
+		{dimension: models.Extraversion, pattern: PatternContrast, kind: NoteComplement, prefix: "Mentor brings social energy while Mentee"},
+		{dimension: models.Conscientiousness, pattern: PatternBothHigh, kind: NoteComplement, prefix: "Mentor and Mentee are both organized"},
+	}
+	if len(comparison.Notes) != len(tests) {
+		t.Fatalf("Expected %d notes, got %+v", len(tests), comparison.Notes)
+	}
+	for i, tt := range tests {
+		t.Run(tt.dimension, func(t *testing.T) {
+			note := comparison.Notes[i]
+			if note.Dimension != tt.dimension || note.Pattern != tt.pattern || note.Kind != tt.kind {
+				t.Errorf("Unexpected note: %+v", note)
+			}
+			if !strings.HasPrefix(note.Text, tt.prefix) {
+				t.Errorf("Expected text to start with %q, got %q", tt.prefix, note.Text)
+			}
+		})
+	}
+
+	// 日本語の注記
+	ja := Compare(inst, a, b, "A", "B", "ja")
+	if !strings.HasPrefix(ja.Notes[0].Text, "BはAより") || strings.Contains(ja.Notes[0].Text, "%!") {
+		t.Errorf("Unexpected Japanese note: %s", ja.Notes[0].Text)
+	}
+}
+
+func TestCompareWithoutCommonDimensions(t *testing.T) {
+	inst := testInstrument(t)
+	a := models.Result{Neuroticism: models.Float64(3)}
+	b := models.Result{Extraversion: models.Float64(3)}
+
+	comparison := Compare(inst, a, b, "A", "B", "en")
+	if comparison.Distance != nil || comparison.Similarity != nil || comparison.Correlation != nil || len(comparison.Notes) != 0 {
+		t.Errorf("Expected no statistics without common dimensions: %+v", comparison)
+	}
+
+	// 同じプロファイルは距離 0、類似度 1。次元が2つのみの場合は相関を計算しない
+	same := models.Result{Neuroticism: models.Float64(2), Extraversion: models.Float64(4)}
+	comparison = Compare(inst, same, same, "A", "B", "en")
+	if *comparison.Distance != 0 || *comparison.Similarity != 1 || comparison.Correlation != nil {
+		t.Errorf("Unexpected comparison of identical profiles: %+v", comparison)
+	}
+}
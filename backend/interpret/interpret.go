@@ -0,0 +1,154 @@
+// Package interpret は診断結果から次元ごとと特徴的な次元の組み合わせの解釈文を選びます
+package interpret
+
+import (
+	"hpcs/i18n"
+	"hpcs/models"
+	"hpcs/norms"
+)
+
+// 解釈に使う水準
+const (
+	Low     = "low"
+	Average = "average"
+	High    = "high"
+)
+
+// 水準の判定基準
+const (
+	// BasisNorms は規準参照得点の水準で判定したことを表します
+	BasisNorms = "norms"
+	// BasisRaw は尺度上の素点の位置で判定したことを表します
+	BasisRaw = "raw"
+)
+
+// 素点で判定する場合の尺度上の位置の閾値（1〜5段階なら 2.5 以下が低い、3.5 以上が高い）
+const (
+	rawLowPosition  = 0.375
+	rawHighPosition = 0.625
+)
+
+// Paragraph は1つの解釈文です
+type Paragraph struct {
+	// Dimension は次元ごとの解釈文の対象の次元です
+	Dimension string `json:"dimension,omitempty"`
+	// Combination は組み合わせの解釈文のIDです
+	Combination string `json:"combination,omitempty"`
+	// Level は次元ごとの解釈文の水準です
+	Level string `json:"level,omitempty"`
+	// Percentile は規準集団におけるパーセンタイル順位です（規準値がない場合は nil）
+	Percentile *float64 `json:"percentile,omitempty"`
+	Text       string   `json:"text"`
+}
+
+// Interpretation は診断結果の解釈です
+type Interpretation struct {
+	// Basis は水準の判定基準です（norms または raw）
+	Basis        string      `json:"basis"`
+	Dimensions   []Paragraph `json:"dimensions"`
+	Combinations []Paragraph `json:"combinations"`
+}
+
+// combination は特徴的な次元の組み合わせです
+type combination struct {
+	ID   string
+	When map[string]string
+}
+
+// combinations は解釈文を持つ次元の組み合わせです（定義順に評価します）
+var combinations = []combination{
+	{ID: "calm_leader", When: map[string]string{models.Extraversion: High, models.Neuroticism: Low}},
+	{ID: "anxious_perfectionist", When: map[string]string{models.Conscientiousness: High, models.Neuroticism: High}},
+	{ID: "creative_explorer", When: map[string]string{models.Openness: High, models.Extraversion: High}},
+	{ID: "reliable_team_player", When: map[string]string{models.Conscientiousness: High, models.Agreeableness: High}},
+	{ID: "independent_thinker", When: map[string]string{models.Openness: High, models.Agreeableness: Low}},
+	{ID: "reflective_analyst", When: map[string]string{models.Openness: High, models.Extraversion: Low}},
+	{ID: "spontaneous_innovator", When: map[string]string{models.Openness: High, models.Conscientiousness: Low}},
+	{ID: "stress_withdrawal", When: map[string]string{models.Neuroticism: High, models.Extraversion: Low}},
+}
+
+// Interpret は診断結果の解釈文を指定した言語で返します。
+// 規準参照得点がある次元はその水準で、ない次元は尺度上の素点の位置で水準を判定します。
+// スコアが算出されなかった次元と、解釈文が用意されていない次元は含まれません
+func Interpret(inst *models.Instrument, result models.Result, profile *norms.Profile, lang string) *Interpretation {
+	interpretation := &Interpretation{
+		Basis:        BasisRaw,
+		Dimensions:   []Paragraph{},
+		Combinations: []Paragraph{},
+	}
+	if profile != nil && len(profile.Dimensions) > 0 {
+		interpretation.Basis = BasisNorms
+	}
+
+	levels := make(map[string]string)
+	for _, dimension := range inst.DimensionIDs() {
+		score, ok := result.Get(dimension)
+		if !ok {
+			continue
+		}
+
+		paragraph := Paragraph{Dimension: dimension, Level: rawLevel(inst, score)}
+		if profile != nil {
+			if s, ok := profile.Dimensions[dimension]; ok {
+				paragraph.Level = bandLevel(s.Band)
+				paragraph.Percentile = models.Float64(s.Percentile)
+			}
+		}
+		levels[dimension] = paragraph.Level
+
+		key := "interpretation." + dimension + "." + paragraph.Level
+		if !i18n.Has(key) {
+			continue
+		}
+		paragraph.Text = i18n.T(lang, key)
+		if paragraph.Percentile != nil {
+			paragraph.Text += " " + i18n.T(lang, "interpretation.percentile", *paragraph.Percentile)
+		}
+		interpretation.Dimensions = append(interpretation.Dimensions, paragraph)
+	}
+
+	for _, c := range combinations {
+		if !c.matches(levels) {
+			continue
+		}
+		interpretation.Combinations = append(interpretation.Combinations, Paragraph{
+			Combination: c.ID,
+			Text:        i18n.T(lang, "interpretation.combination."+c.ID),
+		})
+	}
+
+	return interpretation
+}
+
+// matches は組み合わせの条件をすべての次元が満たすかどうかを返します
+func (c combination) matches(levels map[string]string) bool {
+	for dimension, level := range c.When {
+		if levels[dimension] != level {
+			return false
+		}
+	}
+	return true
+}
+
+// rawLevel は尺度上の素点の位置から水準を判定します
+func rawLevel(inst *models.Instrument, score float64) string {
+	position := (score - float64(inst.Scale.Min)) / float64(inst.Scale.Max-inst.Scale.Min)
+	switch {
+	case position <= rawLowPosition:
+		return Low
+	case position >= rawHighPosition:
+		return High
+	}
+	return Average
+}
+
+// bandLevel は規準参照得点の水準を解釈の水準にまとめます
+func bandLevel(band string) string {
+	switch band {
+	case norms.BandVeryLow, norms.BandLow:
+		return Low
+	case norms.BandHigh, norms.BandVeryHigh:
+		return High
+	}
+	return Average
+}
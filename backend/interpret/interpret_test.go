@@ -0,0 +1,102 @@
+package interpret
+
+import (
+	"hpcs/models"
+	"hpcs/norms"
+	"strings"
+	"testing"
+)
+
+func testInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	inst, err := models.ParseInstrument([]byte(`
+id: test
+scale: {min: 1, max: 5}
+dimensions: [{id: neuroticism}, {id: extraversion}, {id: conscientiousness}, {id: agreeableness}, {id: openness}, {id: custom}]
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	return inst
+}
+
+func TestInterpretRawLevels(t *testing.T) {
+	inst := testInstrument(t)
+	result := models.Result{
+		Neuroticism:       models.Float64(2.0),
+		Extraversion:      models.Float64(4.5),
+		Conscientiousness: models.Float64(3.0),
+		Agreeableness:     nil,
+		Openness:          models.Float64(3.5),
+	}
+	result.Set("custom", models.Float64(5))
+
+	interpretation := Interpret(inst, result, nil, "en")
+
+	if interpretation.Basis != BasisRaw {
+		t.Errorf("Expected basis %s, got %s", BasisRaw, interpretation.Basis)
+	}
+
+	// 算出されなかった協調性と解釈文のない custom は含まれない
+	expected := []struct{ dimension, level string }{
+		{models.Neuroticism, Low},
+		{models.Extraversion, High},
+		{models.Conscientiousness, Average},
+		{models.Openness, High},
+	}
+	if len(interpretation.Dimensions) != len(expected) {
+		t.Fatalf("Expected %d paragraphs, got %d: %+v", len(expected), len(interpretation.Dimensions), interpretation.Dimensions)
+	}
+	for i, want := range expected {
+		got := interpretation.Dimensions[i]
+		if got.Dimension != want.dimension || got.Level != want.level {
+			t.Errorf("Paragraph %d: expected %s/%s, got %s/%s", i, want.dimension, want.level, got.Dimension, got.Level)
+		}
+		if got.Text == "" || got.Percentile != nil {
+			t.Errorf("Paragraph %d: unexpected text or percentile: %+v", i, got)
+		}
+	}
+
+	var ids []string
+	for _, p := range interpretation.Combinations {
+		ids = append(ids, p.Combination)
+	}
+	if strings.Join(ids, ",") != "calm_leader,creative_explorer" {
+		t.Errorf("Unexpected combinations: %v", ids)
+	}
+}
+
+func TestInterpretWithNorms(t *testing.T) {
+	inst := testInstrument(t)
+	result := models.Result{
+		Neuroticism:  models.Float64(4.0),
+		Extraversion: models.Float64(2.0),
+	}
+	profile := &norms.Profile{Dimensions: map[string]norms.Score{
+		// 素点では高いが、規準集団の中では平均的
+		models.Neuroticism:  {Band: norms.BandAverage, Percentile: 52},
+		models.Extraversion: {Band: norms.BandVeryLow, Percentile: 3},
+	}}
+
+	interpretation := Interpret(inst, result, profile, "ja")
+
+	if interpretation.Basis != BasisNorms {
+		t.Errorf("Expected basis %s, got %s", BasisNorms, interpretation.Basis)
+	}
+	if len(interpretation.Dimensions) != 2 {
+		t.Fatalf("Expected 2 paragraphs, got %d", len(interpretation.Dimensions))
+	}
+	neuroticism := interpretation.Dimensions[0]
+	if neuroticism.Level != Average || neuroticism.Percentile == nil || *neuroticism.Percentile != 52 {
+		t.Errorf("Unexpected neuroticism paragraph: %+v", neuroticism)
+	}
+	if !strings.Contains(neuroticism.Text, "規準集団の約52%の人よりも高い得点です") {
+		t.Errorf("Expected percentile sentence, got %s", neuroticism.Text)
+	}
+	if interpretation.Dimensions[1].Level != Low {
+		t.Errorf("Expected very_low band to be interpreted as low, got %s", interpretation.Dimensions[1].Level)
+	}
+	if len(interpretation.Combinations) != 0 {
+		t.Errorf("Expected no combinations, got %+v", interpretation.Combinations)
+	}
+}
@@ -1,26 +1,162 @@
 package main
 
 import (
+	"crypto/rand"
+	"hpcs/auth"
+	"hpcs/cli"
+	"hpcs/data"
 	"hpcs/handlers"
+	"hpcs/norms"
+	"hpcs/storage"
+	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// サブコマンドが指定された場合はサーバーを起動せずに実行
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	serve()
+}
+
+// serve は API サーバーを起動します
+func serve() {
+	// 検査定義の読み込み（未指定の場合は同梱の定義を使用）
+	if dir := os.Getenv("INSTRUMENTS_DIR"); dir != "" {
+		set, err := data.LoadInstrumentSet(dir, os.Getenv("DEFAULT_INSTRUMENT"))
+		if err != nil {
+			log.Fatalf("failed to load instruments from %s: %v", dir, err)
+		}
+		handlers.SetInstruments(set)
+	}
+
+	// 規準値の読み込み（未指定の場合は規準参照得点を計算しない）
+	if dir := os.Getenv("NORMS_DIR"); dir != "" {
+		sets, err := norms.LoadDir(dir)
+		if err != nil {
+			log.Fatalf("failed to load norms from %s: %v", dir, err)
+		}
+		registry, err := norms.NewRegistry(sets)
+		if err != nil {
+			log.Fatalf("failed to load norms from %s: %v", dir, err)
+		}
+		handlers.SetNorms(registry)
+	}
+
+	// 診断結果の保存先
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "hpcs.db"
+	}
+	db, err := storage.OpenBolt(dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	handlers.SetStore(db)
+
+	// トークンの署名鍵（未指定の場合は起動ごとに生成するため、再起動でトークンが無効になる）
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	if len(secret) == 0 {
+		log.Print("JWT_SECRET is not set; using a random secret (tokens will not survive a restart)")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal(err)
+		}
+	}
+	issuer, err := auth.NewIssuer(secret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	handlers.SetAuth(issuer)
+
+	// システム全体の管理者（カンマ区切りのユーザーID。GET /api/auth/me で確認できる）
+	if os.Getenv("SUPERADMIN_EMAILS") != "" {
+		log.Print("SUPERADMIN_EMAILS is no longer supported; set SUPERADMIN_USER_IDS instead")
+	}
+	handlers.SetSuperadmins(strings.Split(os.Getenv("SUPERADMIN_USER_IDS"), ","))
+
+	// チームの構成の集計を公開する最小の人数（未指定の場合は5人）
+	if v := os.Getenv("MIN_GROUP_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Fatalf("invalid MIN_GROUP_SIZE: %s", v)
+		}
+		handlers.SetMinGroupSize(n)
+	}
+
+	// PDF レポートと PNG のチャートの日本語フォント（未指定の場合は英語で作成）
+	handlers.SetReportFont(os.Getenv("REPORT_FONT"))
+
 	r := gin.Default()
 
 	// CORSの設定
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"X-Request-ID"},
 		AllowCredentials: true,
 	}))
 
+	// リクエストIDの付与、応答する言語の決定、エラーレスポンスの共通化と利用者の認証
+	r.Use(handlers.RequestID(), handlers.Language(), handlers.ErrorHandler(), handlers.Authenticate())
+
 	// ルート設定
+	r.POST("/api/auth/register", handlers.Register)
+	r.POST("/api/auth/login", handlers.Login)
+	r.POST("/api/auth/refresh", handlers.Refresh)
+	r.GET("/api/auth/me", handlers.RequireAuth(), handlers.GetCurrentUser)
+	r.GET("/api/instruments", handlers.GetInstruments)
+	r.GET("/api/questions", handlers.GetQuestions)
 	r.POST("/api/calculate", handlers.CalculateScore)
+	r.POST("/api/calculate/batch", handlers.CalculateBatch)
+	r.GET("/api/results", handlers.ListResults)
+	r.GET("/api/export", handlers.ExportResults)
+	r.GET("/api/export/codebook", handlers.GetCodebook)
+	r.GET("/api/results/:id", handlers.AuthorizeResult(), handlers.GetResult)
+	r.GET("/api/results/:id/report.pdf", handlers.AuthorizeResult(), handlers.GetResultReport)
+	r.GET("/api/results/:id/chart.svg", handlers.AuthorizeResult(), handlers.GetResultChartSVG)
+	r.GET("/api/results/:id/chart.png", handlers.AuthorizeResult(), handlers.GetResultChartPNG)
+	r.GET("/api/invitations/:token", handlers.GetInvitation)
+	r.POST("/api/sessions", handlers.CreateSession)
+	r.GET("/api/sessions/:id", handlers.GetSession)
+	r.PUT("/api/sessions/:id/responses", handlers.SaveSessionResponses)
+	r.POST("/api/sessions/:id/complete", handlers.CompleteSession)
+	r.GET("/api/analytics/reliability", handlers.RequireAuth(), handlers.GetReliability)
+	r.POST("/api/analytics/reliability", handlers.PostReliability)
+	r.POST("/api/compare", handlers.CompareResults)
+
+	// 組織、チームと実施の管理（認証が必要）
+	orgs := r.Group("/api", handlers.RequireAuth())
+	orgs.POST("/organizations", handlers.CreateOrganization)
+	orgs.GET("/organizations", handlers.ListOrganizations)
+	orgs.GET("/organizations/:id", handlers.GetOrganization)
+	orgs.GET("/organizations/:id/teams", handlers.ListTeams)
+	orgs.POST("/organizations/:id/teams", handlers.CreateTeam)
+	orgs.GET("/organizations/:id/members", handlers.ListOrganizationMembers)
+	orgs.PUT("/organizations/:id/members", handlers.SaveOrganizationMember)
+	orgs.DELETE("/organizations/:id/members/:userId", handlers.DeleteOrganizationMember)
+	orgs.POST("/organizations/:id/accept", handlers.AcceptOrganizationMembership)
+	orgs.GET("/organizations/:id/campaigns", handlers.ListCampaigns)
+	orgs.POST("/organizations/:id/campaigns", handlers.CreateCampaign)
+	orgs.GET("/teams/:id", handlers.GetTeam)
+	orgs.GET("/teams/:id/members", handlers.ListTeamMembers)
+	orgs.GET("/teams/:id/profile", handlers.GetTeamProfile)
+	orgs.PUT("/teams/:id/members", handlers.SaveTeamMember)
+	orgs.DELETE("/teams/:id/members/:userId", handlers.DeleteTeamMember)
+	orgs.POST("/teams/:id/accept", handlers.AcceptTeamMembership)
+	orgs.GET("/campaigns/:id", handlers.GetCampaign)
+	orgs.GET("/campaigns/:id/invitations", handlers.ListInvitations)
+	orgs.POST("/campaigns/:id/invitations", handlers.CreateInvitations)
+	orgs.GET("/campaigns/:id/dashboard", handlers.GetCampaignDashboard)
 
 	// ポート設定
 	port := os.Getenv("PORT")
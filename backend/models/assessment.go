@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Demographics は規準集団の選択に使う回答者の属性です
+type Demographics struct {
+	AgeGroup string `json:"ageGroup,omitempty"`
+	Gender   string `json:"gender,omitempty"`
+}
+
+// Assessment は保存された1回分の回答と採点結果です
+type Assessment struct {
+	ID                string     `json:"id"`
+	InstrumentID      string     `json:"instrumentId"`
+	InstrumentVersion string     `json:"instrumentVersion"`
+	Responses         []Response `json:"responses"`
+	Result            Result     `json:"result"`
+	// Demographics と NormSet は規準参照得点の計算に使った属性と規準集団です
+	Demographics *Demographics `json:"demographics,omitempty"`
+	NormSet      string        `json:"normSet,omitempty"`
+	// OwnerID は診断結果を所有するユーザーのIDです（匿名で採点した場合は空）
+	OwnerID string `json:"ownerId,omitempty"`
+	// CampaignID は招待から回答した場合の実施のIDです
+	CampaignID string `json:"campaignId,omitempty"`
+	// QualityFlags は採点時に検出された不注意回答のフラグです
+	QualityFlags []string  `json:"qualityFlags,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
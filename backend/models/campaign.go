@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Campaign は「2024年度新入社員」のような、1つの検査で行う実施の単位です
+type Campaign struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organizationId"`
+	// TeamID はチーム単位の実施の場合のチームIDです（空の場合は組織全体）
+	TeamID       string `json:"teamId,omitempty"`
+	Name         string `json:"name"`
+	InstrumentID string `json:"instrumentId"`
+	// NormSet は規準参照得点の計算に使う規準値の名前です
+	NormSet   string    `json:"normSet,omitempty"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Invitation は実施への招待です。Token を知っている参加者はセッションを開始できます
+type Invitation struct {
+	Token      string `json:"token"`
+	CampaignID string `json:"campaignId"`
+	// Email は招待した参加者のメールアドレスです（不特定の参加者向けの場合は空）
+	Email string `json:"email,omitempty"`
+	// MaxUses は使用できる回数です（0 の場合は無制限）
+	MaxUses int `json:"maxUses"`
+	// Uses はセッションの開始に使われた回数です
+	Uses int `json:"uses"`
+	// ExpiresAt は有効期限です（nil の場合は無期限）
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Expired は now の時点で有効期限が切れているかどうかを返します
+func (i Invitation) Expired(now time.Time) bool {
+	return i.ExpiresAt != nil && !now.Before(*i.ExpiresAt)
+}
+
+// Exhausted は使用できる回数を使い切ったかどうかを返します
+func (i Invitation) Exhausted() bool {
+	return i.MaxUses > 0 && i.Uses >= i.MaxUses
+}
@@ -0,0 +1,400 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 逆転項目の採点ルール
+const (
+	// ReverseMirror は尺度の最小値と最大値の和からスコアを引いて反転します（1〜5なら 6 - score）
+	ReverseMirror = "mirror"
+)
+
+// Scale は回答尺度の範囲です
+type Scale struct {
+	Min int `json:"min" yaml:"min"`
+	Max int `json:"max" yaml:"max"`
+}
+
+// Facet は次元の下位側面です
+type Facet struct {
+	ID   string `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// 欠損値の扱い
+const (
+	// MissingProrate は回答済みの項目の平均をスコアとします（個人平均による補完と同じ）
+	MissingProrate = "prorate"
+	// MissingRequireAll は次元のすべての項目に回答した場合のみスコアを算出します
+	MissingRequireAll = "require_all"
+)
+
+// MissingPolicy は回答されなかった項目の扱いです
+type MissingPolicy struct {
+	Method string `json:"method" yaml:"method"`
+	// MinAnsweredFraction はスコアを算出するのに必要な回答済み項目の割合です（0〜1）
+	MinAnsweredFraction float64 `json:"minAnsweredFraction" yaml:"minAnsweredFraction"`
+}
+
+// Sufficient は回答数がスコアの算出に十分かどうかを返します。回答が1つもない場合は常に不十分です
+func (p MissingPolicy) Sufficient(answered, expected int) bool {
+	if answered == 0 {
+		return false
+	}
+	if p.Method == MissingRequireAll {
+		return answered >= expected
+	}
+	return float64(answered)/float64(expected) >= p.MinAnsweredFraction
+}
+
+// QualityRules は不注意回答の検出ルールです。0 の閾値は既定値を使います
+type QualityRules struct {
+	// Pairs は意味的に近く、同じ方向に回答されるはずの質問IDの組です
+	Pairs [][2]int `json:"pairs,omitempty" yaml:"pairs,omitempty"`
+	// MaxLongstring は同じ回答が連続してよい最大の長さです
+	MaxLongstring int `json:"maxLongstring,omitempty" yaml:"maxLongstring,omitempty"`
+	// MinIRV は回答のばらつき（標準偏差）の下限です
+	MinIRV float64 `json:"minIRV,omitempty" yaml:"minIRV,omitempty"`
+	// MaxInconsistency は組になった質問の得点差の平均の上限です
+	MaxInconsistency float64 `json:"maxInconsistency,omitempty" yaml:"maxInconsistency,omitempty"`
+	// MinSecondsPerItem は1問あたりの回答時間の下限です
+	MinSecondsPerItem float64 `json:"minSecondsPerItem,omitempty" yaml:"minSecondsPerItem,omitempty"`
+}
+
+// Dimension は検査が測定する次元です
+type Dimension struct {
+	ID     string  `json:"id" yaml:"id"`
+	Name   string  `json:"name" yaml:"name"`
+	Facets []Facet `json:"facets,omitempty" yaml:"facets,omitempty"`
+}
+
+// DefaultLanguage は検査定義の文言の既定の言語です
+const DefaultLanguage = "ja"
+
+// Translation は検査定義の文言の翻訳です。翻訳のない文言は元の言語のまま使います
+type Translation struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Dimensions は次元IDごとの次元名です
+	Dimensions map[string]string `json:"dimensions,omitempty" yaml:"dimensions,omitempty"`
+	// Facets は下位側面IDごとの下位側面名です
+	Facets map[string]string `json:"facets,omitempty" yaml:"facets,omitempty"`
+	// Items は質問IDごとの質問文です
+	Items map[int]string `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// Instrument は検査（質問紙）の定義です
+type Instrument struct {
+	ID            string      `json:"id" yaml:"id"`
+	Version       string      `json:"version" yaml:"version"`
+	Name          string      `json:"name" yaml:"name"`
+	Scale         Scale       `json:"scale" yaml:"scale"`
+	ReverseKeying string      `json:"reverseKeying" yaml:"reverseKeying"`
+	Dimensions    []Dimension `json:"dimensions" yaml:"dimensions"`
+	Items         []Question  `json:"items" yaml:"items"`
+	// Missing は回答されなかった項目の扱いです
+	Missing MissingPolicy `json:"missing" yaml:"missing"`
+	// Quality は不注意回答の検出ルールです
+	Quality QualityRules `json:"quality" yaml:"quality"`
+	// Language は検査定義の文言の言語です（既定は ja）
+	Language string `json:"language,omitempty" yaml:"language,omitempty"`
+	// Translations は言語ごとの文言の翻訳です
+	Translations map[string]Translation `json:"translations,omitempty" yaml:"translations,omitempty"`
+
+	bank *QuestionBank
+}
+
+// HasFacet は次元が指定した下位側面を持つかどうかを返します
+func (d Dimension) HasFacet(id string) bool {
+	for _, f := range d.Facets {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// init は定義を検証し、質問項目の索引を構築します
+func (inst *Instrument) init() error {
+	if inst.ID == "" {
+		return fmt.Errorf("instrument has no id")
+	}
+	if inst.Scale.Min >= inst.Scale.Max {
+		return fmt.Errorf("instrument %s: invalid scale %d..%d", inst.ID, inst.Scale.Min, inst.Scale.Max)
+	}
+	if inst.ReverseKeying == "" {
+		inst.ReverseKeying = ReverseMirror
+	}
+	if inst.ReverseKeying != ReverseMirror {
+		return fmt.Errorf("instrument %s: unsupported reverse keying rule: %s", inst.ID, inst.ReverseKeying)
+	}
+	if inst.Missing.Method == "" {
+		inst.Missing.Method = MissingProrate
+	}
+	if inst.Missing.Method != MissingProrate && inst.Missing.Method != MissingRequireAll {
+		return fmt.Errorf("instrument %s: unsupported missing data method: %s", inst.ID, inst.Missing.Method)
+	}
+	if inst.Missing.MinAnsweredFraction < 0 || inst.Missing.MinAnsweredFraction > 1 {
+		return fmt.Errorf("instrument %s: minAnsweredFraction must be between 0 and 1", inst.ID)
+	}
+	if len(inst.Dimensions) == 0 {
+		return fmt.Errorf("instrument %s has no dimensions", inst.ID)
+	}
+
+	dimensions := make(map[string]*Dimension, len(inst.Dimensions))
+	for i, d := range inst.Dimensions {
+		if d.ID == "" {
+			return fmt.Errorf("instrument %s: dimension has no id", inst.ID)
+		}
+		if dimensions[d.ID] != nil {
+			return fmt.Errorf("instrument %s: duplicate dimension: %s", inst.ID, d.ID)
+		}
+		dimensions[d.ID] = &inst.Dimensions[i]
+
+		facets := make(map[string]bool, len(d.Facets))
+		for _, f := range d.Facets {
+			if f.ID == "" || facets[f.ID] {
+				return fmt.Errorf("instrument %s: dimension %s has an empty or duplicate facet: %q", inst.ID, d.ID, f.ID)
+			}
+			facets[f.ID] = true
+		}
+	}
+	for _, q := range inst.Items {
+		d := dimensions[q.Category]
+		if d == nil {
+			return fmt.Errorf("instrument %s: question %d has unknown dimension: %s", inst.ID, q.ID, q.Category)
+		}
+		// 下位側面を持つ次元では、すべての質問がいずれかの下位側面に属する
+		if len(d.Facets) > 0 && !d.HasFacet(q.Facet) {
+			return fmt.Errorf("instrument %s: question %d has unknown facet: %q", inst.ID, q.ID, q.Facet)
+		}
+		if len(d.Facets) == 0 && q.Facet != "" {
+			return fmt.Errorf("instrument %s: question %d has a facet but dimension %s defines none", inst.ID, q.ID, d.ID)
+		}
+	}
+
+	bank, err := NewQuestionBank(inst.Items)
+	if err != nil {
+		return fmt.Errorf("instrument %s: %w", inst.ID, err)
+	}
+	for _, pair := range inst.Quality.Pairs {
+		for _, id := range pair {
+			if _, exists := bank.Question(id); !exists {
+				return fmt.Errorf("instrument %s: quality pair refers to unknown question: %d", inst.ID, id)
+			}
+		}
+	}
+	if inst.Language == "" {
+		inst.Language = DefaultLanguage
+	}
+	for lang, t := range inst.Translations {
+		for id := range t.Dimensions {
+			if dimensions[id] == nil {
+				return fmt.Errorf("instrument %s: %s translation refers to unknown dimension: %s", inst.ID, lang, id)
+			}
+		}
+		for id := range t.Items {
+			if _, exists := bank.Question(id); !exists {
+				return fmt.Errorf("instrument %s: %s translation refers to unknown question: %d", inst.ID, lang, id)
+			}
+		}
+	}
+	inst.bank = bank
+	inst.Items = bank.Questions()
+	return nil
+}
+
+// Localize は文言を指定した言語に翻訳した検査定義を返します。
+// 言語が空、元の言語と同じ、または翻訳がない場合は元の定義をそのまま返します
+func (inst *Instrument) Localize(lang string) *Instrument {
+	t, ok := inst.Translations[lang]
+	if lang == "" || lang == inst.Language || !ok {
+		return inst
+	}
+
+	localized := *inst
+	if t.Name != "" {
+		localized.Name = t.Name
+	}
+	localized.Dimensions = make([]Dimension, len(inst.Dimensions))
+	for i, d := range inst.Dimensions {
+		if name, ok := t.Dimensions[d.ID]; ok {
+			d.Name = name
+		}
+		facets := make([]Facet, len(d.Facets))
+		for j, f := range d.Facets {
+			if name, ok := t.Facets[f.ID]; ok {
+				f.Name = name
+			}
+			facets[j] = f
+		}
+		d.Facets = facets
+		localized.Dimensions[i] = d
+	}
+	localized.Items = make([]Question, len(inst.Items))
+	for i, q := range inst.Items {
+		if text, ok := t.Items[q.ID]; ok {
+			q.Text = text
+		}
+		localized.Items[i] = q
+	}
+	// 元の定義で検証済みのため失敗しない
+	localized.bank, _ = NewQuestionBank(localized.Items)
+	return &localized
+}
+
+// Bank は検査の質問項目を返します
+func (inst *Instrument) Bank() *QuestionBank {
+	return inst.bank
+}
+
+// DimensionIDs は検査が測定する次元のIDを定義順に返します
+func (inst *Instrument) DimensionIDs() []string {
+	ids := make([]string, len(inst.Dimensions))
+	for i, d := range inst.Dimensions {
+		ids[i] = d.ID
+	}
+	return ids
+}
+
+// Dimension は指定したIDの次元を返します
+func (inst *Instrument) Dimension(id string) (Dimension, bool) {
+	for _, d := range inst.Dimensions {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return Dimension{}, false
+}
+
+// InRange はスコアが尺度の範囲内かどうかを返します
+func (inst *Instrument) InRange(score int) bool {
+	return score >= inst.Scale.Min && score <= inst.Scale.Max
+}
+
+// KeyedScore は逆転項目の採点ルールを適用したスコアを返します
+func (inst *Instrument) KeyedScore(q Question, score int) float64 {
+	if !q.IsReverse {
+		return float64(score)
+	}
+	return float64(inst.Scale.Min + inst.Scale.Max - score)
+}
+
+// ParseInstrument は YAML または JSON の検査定義を読み込みます
+func ParseInstrument(data []byte, format string) (*Instrument, error) {
+	var inst Instrument
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &inst); err != nil {
+			return nil, fmt.Errorf("failed to decode instrument: %w", err)
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(data, &inst); err != nil {
+			return nil, fmt.Errorf("failed to decode instrument: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported instrument format: %s", format)
+	}
+
+	if err := inst.init(); err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+// instrumentFormat はファイルの拡張子から定義の形式を判定します
+func instrumentFormat(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return ""
+}
+
+// LoadInstruments はファイルシステム直下の定義ファイルをすべて読み込みます
+func LoadInstruments(fsys fs.FS) ([]*Instrument, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var instruments []*Instrument
+	for _, entry := range entries {
+		format := instrumentFormat(entry.Name())
+		if entry.IsDir() || format == "" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		inst, err := ParseInstrument(data, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		instruments = append(instruments, inst)
+	}
+
+	return instruments, nil
+}
+
+// LoadInstrumentsDir はディレクトリ内の定義ファイルをすべて読み込みます
+func LoadInstrumentsDir(dir string) ([]*Instrument, error) {
+	return LoadInstruments(os.DirFS(dir))
+}
+
+// InstrumentSet は読み込み済みの検査定義の集合です
+type InstrumentSet struct {
+	byID      map[string]*Instrument
+	defaultID string
+}
+
+// NewInstrumentSet は検査定義の集合を作成します
+func NewInstrumentSet(instruments []*Instrument, defaultID string) (*InstrumentSet, error) {
+	byID := make(map[string]*Instrument, len(instruments))
+	for _, inst := range instruments {
+		if _, exists := byID[inst.ID]; exists {
+			return nil, fmt.Errorf("duplicate instrument: %s", inst.ID)
+		}
+		byID[inst.ID] = inst
+	}
+	if _, exists := byID[defaultID]; !exists {
+		return nil, fmt.Errorf("default instrument not found: %s", defaultID)
+	}
+	return &InstrumentSet{byID: byID, defaultID: defaultID}, nil
+}
+
+// Get は指定したIDの検査定義を返します。IDが空の場合はデフォルトの検査を返します
+func (s *InstrumentSet) Get(id string) (*Instrument, bool) {
+	if id == "" {
+		id = s.defaultID
+	}
+	inst, ok := s.byID[id]
+	return inst, ok
+}
+
+// Default はデフォルトの検査定義を返します
+func (s *InstrumentSet) Default() *Instrument {
+	return s.byID[s.defaultID]
+}
+
+// List は ID 順に並んだ全検査定義を返します
+func (s *InstrumentSet) List() []*Instrument {
+	instruments := make([]*Instrument, 0, len(s.byID))
+	for _, inst := range s.byID {
+		instruments = append(instruments, inst)
+	}
+	sort.Slice(instruments, func(i, j int) bool { return instruments[i].ID < instruments[j].ID })
+	return instruments
+}
@@ -0,0 +1,255 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadInstrumentsDir(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"short.yaml": `
+id: short
+version: "1.0.0"
+scale: {min: 1, max: 5}
+dimensions:
+  - {id: neuroticism, name: 神経症傾向}
+items:
+  - {id: 1, text: 心配性である, category: neuroticism}
+  - {id: 2, text: ストレスに強い, category: neuroticism, isReverse: true}
+`,
+		"work.json": `{
+  "id": "work",
+  "version": "2.0.0",
+  "scale": {"min": 0, "max": 6},
+  "dimensions": [{"id": "teamwork"}],
+  "items": [{"id": 10, "text": "協力的である", "category": "teamwork"}]
+}`,
+		"README.txt": "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := LoadInstrumentsDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to load instruments: %v", err)
+	}
+
+	set, err := NewInstrumentSet(list, "short")
+	if err != nil {
+		t.Fatalf("Failed to create instrument set: %v", err)
+	}
+
+	if got := len(set.List()); got != 2 {
+		t.Fatalf("Expected 2 instruments, got %d", got)
+	}
+
+	short, ok := set.Get("")
+	if !ok || short.ID != "short" {
+		t.Fatalf("Expected default instrument to be short, got %+v", short)
+	}
+	if short.ReverseKeying != ReverseMirror {
+		t.Errorf("Expected default reverse keying rule to be %s, got %s", ReverseMirror, short.ReverseKeying)
+	}
+
+	q, _ := short.Bank().Question(2)
+	if got := short.KeyedScore(q, 1); got != 5 {
+		t.Errorf("Expected reversed score to be 5, got %f", got)
+	}
+
+	work, ok := set.Get("work")
+	if !ok {
+		t.Fatal("Expected work instrument to be loaded")
+	}
+	q, _ = work.Bank().Question(10)
+	if !work.InRange(0) || work.InRange(7) {
+		t.Errorf("Expected scale 0..6, got %+v", work.Scale)
+	}
+	if got := work.KeyedScore(q, 4); got != 4 {
+		t.Errorf("Expected keyed score to be 4, got %f", got)
+	}
+}
+
+func TestParseInstrumentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "IDなし",
+			input:    `{"scale":{"min":1,"max":5},"dimensions":[{"id":"a"}]}`,
+			expected: "instrument has no id",
+		},
+		{
+			name:     "不正な尺度",
+			input:    `{"id":"x","scale":{"min":5,"max":1},"dimensions":[{"id":"a"}]}`,
+			expected: "invalid scale",
+		},
+		{
+			name:     "未定義の次元",
+			input:    `{"id":"x","scale":{"min":1,"max":5},"dimensions":[{"id":"a"}],"items":[{"id":1,"category":"b"}]}`,
+			expected: "question 1 has unknown dimension: b",
+		},
+		{
+			name:     "未対応の逆転ルール",
+			input:    `{"id":"x","scale":{"min":1,"max":5},"reverseKeying":"negate","dimensions":[{"id":"a"}]}`,
+			expected: "unsupported reverse keying rule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInstrument([]byte(tt.input), "json")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing '%s', got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestNewInstrumentSetUnknownDefault(t *testing.T) {
+	if _, err := NewInstrumentSet(nil, "hpcs-74"); err == nil {
+		t.Error("Expected error for missing default instrument")
+	}
+}
+
+func TestParseInstrumentFacets(t *testing.T) {
+	valid := `
+id: facets
+scale: {min: 1, max: 5}
+dimensions:
+  - id: openness
+    facets:
+      - {id: artistic}
+      - {id: analytical}
+items:
+  - {id: 1, category: openness, facet: artistic}
+  - {id: 2, category: openness, facet: analytical}
+`
+	inst, err := ParseInstrument([]byte(valid), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	d, ok := inst.Dimension("openness")
+	if !ok || !d.HasFacet("artistic") || d.HasFacet("vision") {
+		t.Errorf("Unexpected facets: %+v", d.Facets)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "下位側面が未指定",
+			input:    `{"id":"x","scale":{"min":1,"max":5},"dimensions":[{"id":"a","facets":[{"id":"f"}]}],"items":[{"id":1,"category":"a"}]}`,
+			expected: "question 1 has unknown facet",
+		},
+		{
+			name:     "下位側面のない次元",
+			input:    `{"id":"x","scale":{"min":1,"max":5},"dimensions":[{"id":"a"}],"items":[{"id":1,"category":"a","facet":"f"}]}`,
+			expected: "defines none",
+		},
+		{
+			name:     "重複した下位側面",
+			input:    `{"id":"x","scale":{"min":1,"max":5},"dimensions":[{"id":"a","facets":[{"id":"f"},{"id":"f"}]}]}`,
+			expected: "duplicate facet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInstrument([]byte(tt.input), "json")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing '%s', got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestMissingPolicySufficient(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   MissingPolicy
+		answered int
+		expected int
+		want     bool
+	}{
+		{name: "未回答", policy: MissingPolicy{Method: MissingProrate}, answered: 0, expected: 4, want: false},
+		{name: "下限なし", policy: MissingPolicy{Method: MissingProrate}, answered: 1, expected: 4, want: true},
+		{name: "下限ちょうど", policy: MissingPolicy{Method: MissingProrate, MinAnsweredFraction: 0.5}, answered: 2, expected: 4, want: true},
+		{name: "下限未満", policy: MissingPolicy{Method: MissingProrate, MinAnsweredFraction: 0.5}, answered: 1, expected: 4, want: false},
+		{name: "全問必須", policy: MissingPolicy{Method: MissingRequireAll}, answered: 3, expected: 4, want: false},
+		{name: "全問回答", policy: MissingPolicy{Method: MissingRequireAll}, answered: 4, expected: 4, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Sufficient(tt.answered, tt.expected); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ParseInstrument([]byte(`{"id":"x","scale":{"min":1,"max":5},"missing":{"method":"impute"},"dimensions":[{"id":"a"}]}`), "json"); err == nil {
+		t.Error("Expected error for unsupported missing data method")
+	}
+}
+
+func TestInstrumentLocalize(t *testing.T) {
+	inst, err := ParseInstrument([]byte(`
+id: test
+scale: {min: 1, max: 5}
+dimensions:
+  - id: neuroticism
+    name: 神経症傾向
+    facets: [{id: anxiety, name: 不安}]
+  - id: openness
+    name: 開放性
+items:
+  - {id: 1, text: 心配性である, category: neuroticism, facet: anxiety}
+  - {id: 2, text: 好奇心が強い, category: openness}
+translations:
+  en:
+    name: Test
+    dimensions: {neuroticism: Neuroticism}
+    facets: {anxiety: Anxiety}
+    items: {1: I tend to worry}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	if inst.Language != DefaultLanguage {
+		t.Errorf("Expected default language %s, got %s", DefaultLanguage, inst.Language)
+	}
+
+	en := inst.Localize("en")
+	if en.Name != "Test" || en.Dimensions[0].Name != "Neuroticism" || en.Dimensions[0].Facets[0].Name != "Anxiety" {
+		t.Errorf("Unexpected localized names: %+v", en.Dimensions)
+	}
+	if q, _ := en.Bank().Question(1); q.Text != "I tend to worry" {
+		t.Errorf("Unexpected localized question: %s", q.Text)
+	}
+	// 翻訳のない文言は元の言語のまま
+	if q, _ := en.Bank().Question(2); q.Text != "好奇心が強い" || en.Dimensions[1].Name != "開放性" {
+		t.Errorf("Expected untranslated text to be kept, got %s", q.Text)
+	}
+	// 元の定義は変更されない
+	if q, _ := inst.Bank().Question(1); q.Text != "心配性である" || inst.Dimensions[0].Name != "神経症傾向" {
+		t.Errorf("Localize should not modify the original instrument")
+	}
+	if inst.Localize("ja") != inst || inst.Localize("fr") != inst || inst.Localize("") != inst {
+		t.Error("Expected the original instrument for the source or unknown language")
+	}
+
+	if _, err := ParseInstrument([]byte(`{"id":"x","scale":{"min":1,"max":5},"dimensions":[{"id":"a"}],"translations":{"en":{"items":{"9":"x"}}}}`), "json"); err == nil {
+		t.Error("Expected error for translation of unknown question")
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Role は組織やチームでの利用者の役割です
+type Role string
+
+const (
+	// RoleParticipant は検査を受ける参加者です。自分の診断結果のみを参照できます
+	RoleParticipant Role = "participant"
+	// RoleManager はチームの管理者です。チームのメンバーの診断結果を参照できます
+	RoleManager Role = "manager"
+	// RoleOrgAdmin は組織の管理者です。組織のすべてのメンバーの診断結果を参照し、チームとメンバーを管理できます
+	RoleOrgAdmin Role = "org_admin"
+	// RoleSuperadmin はシステム全体の管理者です。組織の作成とすべての診断結果の参照ができます
+	RoleSuperadmin Role = "superadmin"
+)
+
+// Organization は検査を利用する会社などの組織です
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Team は組織内の部署などのチームです
+type Team struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organizationId"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Membership は利用者の組織またはチームへの所属と役割です。
+// TeamID が空の場合は組織への所属で、役割は participant または org_admin になります。
+// それ以外の場合はチームへの所属で、役割は participant または manager になります。
+// 他の利用者が追加した所属は、本人が承諾するまで権限にも診断結果の参照にも使われません
+type Membership struct {
+	OrganizationID string    `json:"organizationId"`
+	TeamID         string    `json:"teamId,omitempty"`
+	UserID         string    `json:"userId"`
+	Role           Role      `json:"role"`
+	CreatedAt      time.Time `json:"createdAt"`
+	// AcceptedAt は本人が所属を承諾した日時です（承諾していない場合は nil）
+	AcceptedAt *time.Time `json:"acceptedAt"`
+}
+
+// ValidRole は所属先で役割を使えるかどうかを返します
+func (m Membership) ValidRole() bool {
+	switch m.Role {
+	case RoleParticipant:
+		return true
+	case RoleManager:
+		return m.TeamID != ""
+	case RoleOrgAdmin:
+		return m.TeamID == ""
+	}
+	return false
+}
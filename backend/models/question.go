@@ -1,10 +1,12 @@
 package models
 
 type Question struct {
-	ID        int    `json:"id"`
-	Text      string `json:"text"`
-	Category  string `json:"category"`
-	IsReverse bool   `json:"isReverse"`
+	ID        int    `json:"id" yaml:"id"`
+	Text      string `json:"text" yaml:"text"`
+	Category  string `json:"category" yaml:"category"`
+	IsReverse bool   `json:"isReverse" yaml:"isReverse"`
+	// Facet は質問が属する下位側面のIDです（次元が下位側面を持つ場合のみ）
+	Facet string `json:"facet,omitempty" yaml:"facet,omitempty"`
 }
 
 type Response struct {
@@ -12,10 +14,82 @@ type Response struct {
 	Score      int `json:"score"`
 }
 
+// ビッグファイブの各次元のID
+const (
+	Neuroticism       = "neuroticism"
+	Extraversion      = "extraversion"
+	Conscientiousness = "conscientiousness"
+	Agreeableness     = "agreeableness"
+	Openness          = "openness"
+)
+
+// BigFive は Result が固定のフィールドとして持つ次元のIDです
+var BigFive = []string{Neuroticism, Extraversion, Conscientiousness, Agreeableness, Openness}
+
+// Result は次元ごとの平均スコアです。回答が不足して算出できない次元は nil になります
 type Result struct {
-	Neuroticism       float64 `json:"neuroticism"`
-	Extraversion      float64 `json:"extraversion"`
-	Conscientiousness float64 `json:"conscientiousness"`
-	Agreeableness     float64 `json:"agreeableness"`
-	Openness          float64 `json:"openness"`
+	Neuroticism       *float64 `json:"neuroticism"`
+	Extraversion      *float64 `json:"extraversion"`
+	Conscientiousness *float64 `json:"conscientiousness"`
+	Agreeableness     *float64 `json:"agreeableness"`
+	Openness          *float64 `json:"openness"`
+	// Extra はビッグファイブ以外の次元のスコアです（独自尺度の検査で使用）
+	Extra map[string]*float64 `json:"extra,omitempty"`
+	// Facets は次元ごとの下位側面の平均スコアです（次元ID → 下位側面ID → スコア）
+	Facets map[string]map[string]float64 `json:"facets,omitempty"`
+	// Coverage は次元ごとの回答数と、スコアの算出に十分だったかどうかです
+	Coverage map[string]DimensionCoverage `json:"coverage,omitempty"`
+}
+
+// DimensionCoverage は1つの次元の回答状況です
+type DimensionCoverage struct {
+	Answered   int  `json:"answered"`
+	Expected   int  `json:"expected"`
+	Sufficient bool `json:"sufficient"`
+}
+
+// Float64 はスコアのポインタを返します
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// field は次元に対応するフィールドを返します。ビッグファイブ以外の場合は nil を返します
+func (r *Result) field(dimension string) **float64 {
+	switch dimension {
+	case Neuroticism:
+		return &r.Neuroticism
+	case Extraversion:
+		return &r.Extraversion
+	case Conscientiousness:
+		return &r.Conscientiousness
+	case Agreeableness:
+		return &r.Agreeableness
+	case Openness:
+		return &r.Openness
+	}
+	return nil
+}
+
+// Get は指定した次元のスコアを返します。スコアがない場合は false を返します
+func (r Result) Get(dimension string) (float64, bool) {
+	score := r.Extra[dimension]
+	if f := r.field(dimension); f != nil {
+		score = *f
+	}
+	if score == nil {
+		return 0, false
+	}
+	return *score, true
+}
+
+// Set は指定した次元のスコアを設定します。nil は回答不足で算出できないことを表します
+func (r *Result) Set(dimension string, score *float64) {
+	if f := r.field(dimension); f != nil {
+		*f = score
+		return
+	}
+	if r.Extra == nil {
+		r.Extra = make(map[string]*float64)
+	}
+	r.Extra[dimension] = score
 }
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// QuestionBank は読み込み済みの質問項目の集合です
+type QuestionBank struct {
+	questions []Question
+	byID      map[int]Question
+}
+
+// NewQuestionBank は質問項目のリストから QuestionBank を作成します
+func NewQuestionBank(questions []Question) (*QuestionBank, error) {
+	byID := make(map[int]Question, len(questions))
+	for _, q := range questions {
+		if q.ID <= 0 {
+			return nil, fmt.Errorf("invalid question ID: %d", q.ID)
+		}
+		if q.Category == "" {
+			return nil, fmt.Errorf("question %d has no category", q.ID)
+		}
+		if _, exists := byID[q.ID]; exists {
+			return nil, fmt.Errorf("duplicate question ID: %d", q.ID)
+		}
+		byID[q.ID] = q
+	}
+
+	sorted := make([]Question, len(questions))
+	copy(sorted, questions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	return &QuestionBank{questions: sorted, byID: byID}, nil
+}
+
+// Questions は ID 順に並んだ全質問項目を返します
+func (b *QuestionBank) Questions() []Question {
+	questions := make([]Question, len(b.questions))
+	copy(questions, b.questions)
+	return questions
+}
+
+// Question は指定した ID の質問項目を返します
+func (b *QuestionBank) Question(id int) (Question, bool) {
+	q, ok := b.byID[id]
+	return q, ok
+}
+
+// ByCategory は指定したカテゴリーに属する質問項目を返します
+func (b *QuestionBank) ByCategory(category string) []Question {
+	var questions []Question
+	for _, q := range b.questions {
+		if q.Category == category {
+			questions = append(questions, q)
+		}
+	}
+	return questions
+}
+
+// Len は質問項目の数を返します
+func (b *QuestionBank) Len() int {
+	return len(b.questions)
+}
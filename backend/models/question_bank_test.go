@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuestionBank(t *testing.T) {
+	bank, err := NewQuestionBank([]Question{
+		{ID: 2, Text: "心配性である", Category: "neuroticism"},
+		{ID: 1, Text: "ストレスに強い", Category: "neuroticism", IsReverse: true},
+		{ID: 3, Text: "社交的である", Category: "extraversion"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create question bank: %v", err)
+	}
+
+	if bank.Len() != 3 {
+		t.Errorf("Expected 3 questions, got %d", bank.Len())
+	}
+
+	// ID順に並び替えられること
+	questions := bank.Questions()
+	if questions[0].ID != 1 || questions[2].ID != 3 {
+		t.Errorf("Expected questions sorted by ID, got %+v", questions)
+	}
+
+	q, ok := bank.Question(1)
+	if !ok || !q.IsReverse {
+		t.Errorf("Expected question 1 to be a reversed item, got %+v", q)
+	}
+
+	if got := len(bank.ByCategory("neuroticism")); got != 2 {
+		t.Errorf("Expected 2 neuroticism questions, got %d", got)
+	}
+}
+
+func TestNewQuestionBankErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		questions []Question
+		expected  string
+	}{
+		{
+			name: "重複した質問ID",
+			questions: []Question{
+				{ID: 1, Category: "neuroticism"},
+				{ID: 1, Category: "openness"},
+			},
+			expected: "duplicate question ID: 1",
+		},
+		{
+			name:      "不正な質問ID",
+			questions: []Question{{ID: 0, Category: "neuroticism"}},
+			expected:  "invalid question ID: 0",
+		},
+		{
+			name:      "カテゴリー未設定",
+			questions: []Question{{ID: 5}},
+			expected:  "question 5 has no category",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewQuestionBank(tt.questions)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing '%s', got %v", tt.expected, err)
+			}
+		})
+	}
+}
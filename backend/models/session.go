@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// セッションの状態
+const (
+	SessionInProgress = "in_progress"
+	SessionCompleted  = "completed"
+)
+
+// Session は回答途中の診断セッションです。ID はセッショントークンを兼ねます
+type Session struct {
+	ID           string     `json:"id"`
+	InstrumentID string     `json:"instrumentId"`
+	Status       string     `json:"status"`
+	Responses    []Response `json:"responses"`
+	// Demographics と NormSet は完了時の規準参照得点の計算に使います
+	Demographics *Demographics `json:"demographics,omitempty"`
+	NormSet      string        `json:"normSet,omitempty"`
+	// OwnerID はセッションを開始したユーザーのIDです（完了時の診断結果の所有者になります）
+	OwnerID string `json:"ownerId,omitempty"`
+	// CampaignID と InvitationToken は招待から開始したセッションの実施と招待です
+	CampaignID      string `json:"campaignId,omitempty"`
+	InvitationToken string `json:"invitationToken,omitempty"`
+	// AssessmentID は完了時に確定した診断結果のIDです
+	AssessmentID string `json:"assessmentId,omitempty"`
+	// Outcome は完了時に確定した規準参照得点と品質指標を含む採点結果です（完了後はこの結果を返します）
+	Outcome     json.RawMessage `json:"outcome,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+}
+
+// Upsert は質問IDごとに回答を追加または上書きします
+func (s *Session) Upsert(responses []Response) {
+	index := make(map[int]int, len(s.Responses))
+	for i, r := range s.Responses {
+		index[r.QuestionID] = i
+	}
+	for _, r := range responses {
+		if i, exists := index[r.QuestionID]; exists {
+			s.Responses[i] = r
+			continue
+		}
+		index[r.QuestionID] = len(s.Responses)
+		s.Responses = append(s.Responses, r)
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// User は診断結果を所有する利用者のアカウントです
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	// PasswordHash は bcrypt でハッシュ化したパスワードです（レスポンスには含めません）
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
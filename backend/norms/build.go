@@ -0,0 +1,176 @@
+package norms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultPercentiles は規準値の作成時に求めるパーセンタイルの区切りです
+var DefaultPercentiles = []float64{1, 5, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99}
+
+// Sample は規準値の作成に使う1人分の素点と属性です
+type Sample struct {
+	Scores   map[string]float64
+	AgeGroup string
+	Gender   string
+}
+
+// BuildOptions は規準値の作成方法です
+type BuildOptions struct {
+	Name         string
+	InstrumentID string
+	Description  string
+	// Dimensions は規準値を求める次元のIDです
+	Dimensions []string
+	// Percentiles はパーセンタイル表の区切りです（空の場合は DefaultPercentiles。順序と重複は問いません）
+	Percentiles []float64
+	// GroupByAge と GroupByGender が true の場合は属性ごとのグループも作成します
+	GroupByAge    bool
+	GroupByGender bool
+	// MinGroupSize より人数が少ないグループは作成しません
+	MinGroupSize int
+}
+
+// Build は標本から各次元の平均・標準偏差・パーセンタイル表を求めて規準値を作成します
+func Build(samples []Sample, opts BuildOptions) (*NormSet, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("norm set has no name")
+	}
+	if len(opts.Percentiles) == 0 {
+		opts.Percentiles = DefaultPercentiles
+	}
+	opts.Percentiles = sortedPercentiles(opts.Percentiles)
+
+	set := &NormSet{
+		Name:         opts.Name,
+		InstrumentID: opts.InstrumentID,
+		Description:  opts.Description,
+		Dimensions:   buildDimensions(samples, opts),
+	}
+	if len(set.Dimensions) == 0 {
+		return nil, fmt.Errorf("not enough samples to build norms: %d", len(samples))
+	}
+
+	if opts.GroupByAge || opts.GroupByGender {
+		set.Groups = buildGroups(samples, opts)
+	}
+	return set, nil
+}
+
+// sortedPercentiles はパーセンタイルの区切りを昇順に並べ、重複を除いたコピーを返します。
+// パーセンタイル表は得点の昇順に並んでいる必要があるためです
+func sortedPercentiles(percentiles []float64) []float64 {
+	sorted := make([]float64, len(percentiles))
+	copy(sorted, percentiles)
+	sort.Float64s(sorted)
+	unique := sorted[:0]
+	for i, p := range sorted {
+		if i == 0 || p != sorted[i-1] {
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// buildGroups は属性ごとに標本を分けて規準値を求めます
+func buildGroups(samples []Sample, opts BuildOptions) []Group {
+	type key struct{ ageGroup, gender string }
+	byKey := make(map[key][]Sample)
+	var keys []key
+	for _, s := range samples {
+		var k key
+		if opts.GroupByAge {
+			k.ageGroup = s.AgeGroup
+		}
+		if opts.GroupByGender {
+			k.gender = s.Gender
+		}
+		// 属性が未回答の標本はグループに含めない
+		if (opts.GroupByAge && k.ageGroup == "") || (opts.GroupByGender && k.gender == "") {
+			continue
+		}
+		if _, exists := byKey[k]; !exists {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], s)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ageGroup != keys[j].ageGroup {
+			return keys[i].ageGroup < keys[j].ageGroup
+		}
+		return keys[i].gender < keys[j].gender
+	})
+
+	var groups []Group
+	for _, k := range keys {
+		if len(byKey[k]) < opts.MinGroupSize {
+			continue
+		}
+		dimensions := buildDimensions(byKey[k], opts)
+		if len(dimensions) == 0 {
+			continue
+		}
+		groups = append(groups, Group{AgeGroup: k.ageGroup, Gender: k.gender, Dimensions: dimensions})
+	}
+	return groups
+}
+
+// buildDimensions は次元ごとの規準値を求めます。標本が2件未満の次元は含まれません
+func buildDimensions(samples []Sample, opts BuildOptions) map[string]DimensionNorm {
+	dimensions := make(map[string]DimensionNorm, len(opts.Dimensions))
+	for _, dimension := range opts.Dimensions {
+		var values []float64
+		for _, s := range samples {
+			if v, ok := s.Scores[dimension]; ok {
+				values = append(values, v)
+			}
+		}
+		if len(values) < 2 {
+			continue
+		}
+
+		mean, sd := meanSD(values)
+		if sd == 0 {
+			continue
+		}
+
+		sort.Float64s(values)
+		breakpoints := make([]Breakpoint, len(opts.Percentiles))
+		for i, p := range opts.Percentiles {
+			breakpoints[i] = Breakpoint{Score: round(quantile(values, p/100), 3), Percentile: p}
+		}
+
+		dimensions[dimension] = DimensionNorm{
+			Mean:        round(mean, 3),
+			SD:          round(sd, 3),
+			N:           len(values),
+			Percentiles: breakpoints,
+		}
+	}
+	return dimensions
+}
+
+// meanSD は平均と不偏標準偏差を求めます
+func meanSD(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var ss float64
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(values)-1))
+}
+
+// quantile は昇順に並んだ値の分位点を線形補間で求めます
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,105 @@
+package norms
+
+import (
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	var samples []Sample
+	for i := 1; i <= 5; i++ {
+		samples = append(samples, Sample{
+			Scores:   map[string]float64{"openness": float64(i), "neuroticism": 3},
+			AgeGroup: "20s",
+			Gender:   "female",
+		})
+	}
+	samples = append(samples, Sample{Scores: map[string]float64{"openness": 3}, AgeGroup: "30s"})
+
+	set, err := Build(samples, BuildOptions{
+		Name:         "staff",
+		InstrumentID: "hpcs-74",
+		Dimensions:   []string{"openness", "neuroticism", "extraversion"},
+		Percentiles:  []float64{0, 50, 100},
+		GroupByAge:   true,
+		MinGroupSize: 3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build norms: %v", err)
+	}
+
+	openness := set.Dimensions["openness"]
+	if openness.N != 6 || !almostEqual(openness.Mean, 3.0, 0.001) || !almostEqual(openness.SD, 1.414, 0.001) {
+		t.Errorf("Unexpected openness norm: %+v", openness)
+	}
+	expected := []Breakpoint{{Score: 1, Percentile: 0}, {Score: 3, Percentile: 50}, {Score: 5, Percentile: 100}}
+	for i, bp := range openness.Percentiles {
+		if bp != expected[i] {
+			t.Errorf("Expected breakpoint %+v, got %+v", expected[i], bp)
+		}
+	}
+
+	// 分散が0の次元と回答のない次元は含まれない
+	if _, exists := set.Dimensions["neuroticism"]; exists {
+		t.Error("Expected zero-variance dimension to be omitted")
+	}
+	if _, exists := set.Dimensions["extraversion"]; exists {
+		t.Error("Expected dimension without samples to be omitted")
+	}
+
+	// 最小人数に満たないグループは作成されない
+	if len(set.Groups) != 1 || set.Groups[0].AgeGroup != "20s" || set.Groups[0].Gender != "" {
+		t.Fatalf("Expected only the 20s group, got %+v", set.Groups)
+	}
+	if got := set.Groups[0].Dimensions["openness"]; got.N != 5 {
+		t.Errorf("Expected 5 samples in the 20s group, got %d", got.N)
+	}
+
+	// 作成した規準値はそのまま読み込める
+	if err := set.validate(); err != nil {
+		t.Errorf("Expected built norm set to be valid, got %v", err)
+	}
+}
+
+func TestBuildUnsortedPercentiles(t *testing.T) {
+	var samples []Sample
+	for i := 1; i <= 5; i++ {
+		samples = append(samples, Sample{Scores: map[string]float64{"openness": float64(i)}})
+	}
+	percentiles := []float64{90, 10, 50, 10}
+
+	set, err := Build(samples, BuildOptions{Name: "staff", Dimensions: []string{"openness"}, Percentiles: percentiles})
+	if err != nil {
+		t.Fatalf("Failed to build norms: %v", err)
+	}
+
+	// 区切りは昇順に並べ替え、重複を除く
+	expected := []Breakpoint{{Score: 1.4, Percentile: 10}, {Score: 3, Percentile: 50}, {Score: 4.6, Percentile: 90}}
+	got := set.Dimensions["openness"].Percentiles
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d breakpoints, got %+v", len(expected), got)
+	}
+	for i, bp := range got {
+		if bp != expected[i] {
+			t.Errorf("Expected breakpoint %+v, got %+v", expected[i], bp)
+		}
+	}
+	if err := set.validate(); err != nil {
+		t.Errorf("Expected built norm set to be valid, got %v", err)
+	}
+	// 呼び出し元の区切りは変更しない
+	if percentiles[0] != 90 {
+		t.Errorf("Expected options not to be modified, got %v", percentiles)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	if _, err := Build(nil, BuildOptions{Dimensions: []string{"openness"}}); err == nil {
+		t.Error("Expected error for missing name")
+	}
+	if _, err := Build([]Sample{{Scores: map[string]float64{"openness": 3}}}, BuildOptions{
+		Name:       "x",
+		Dimensions: []string{"openness"},
+	}); err == nil {
+		t.Error("Expected error for insufficient samples")
+	}
+}
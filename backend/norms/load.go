@@ -0,0 +1,110 @@
+package norms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse は YAML または JSON の規準値ファイルを読み込みます
+func Parse(data []byte, format string) (*NormSet, error) {
+	var set NormSet
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("failed to decode norm set: %w", err)
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("failed to decode norm set: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported norm set format: %s", format)
+	}
+
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Load はファイルシステム直下の規準値ファイルをすべて読み込みます
+func Load(fsys fs.FS) ([]*NormSet, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []*NormSet
+	for _, entry := range entries {
+		var format string
+		switch strings.ToLower(path.Ext(entry.Name())) {
+		case ".json":
+			format = "json"
+		case ".yaml", ".yml":
+			format = "yaml"
+		}
+		if entry.IsDir() || format == "" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		set, err := Parse(data, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		sets = append(sets, set)
+	}
+
+	return sets, nil
+}
+
+// LoadDir はディレクトリ内の規準値ファイルをすべて読み込みます
+func LoadDir(dir string) ([]*NormSet, error) {
+	return Load(os.DirFS(dir))
+}
+
+// Registry は読み込み済みの規準値の集合です
+type Registry struct {
+	byName map[string]*NormSet
+	names  []string
+}
+
+// NewRegistry は規準値の集合を作成します
+func NewRegistry(sets []*NormSet) (*Registry, error) {
+	r := &Registry{byName: make(map[string]*NormSet, len(sets))}
+	for _, set := range sets {
+		if _, exists := r.byName[set.Name]; exists {
+			return nil, fmt.Errorf("duplicate norm set: %s", set.Name)
+		}
+		r.byName[set.Name] = set
+		r.names = append(r.names, set.Name)
+	}
+	sort.Strings(r.names)
+	return r, nil
+}
+
+// Get は指定した名前の規準値を返します
+func (r *Registry) Get(name string) (*NormSet, bool) {
+	set, ok := r.byName[name]
+	return set, ok
+}
+
+// ForInstrument は検査に対応する規準値のうち名前順で最初のものを返します
+func (r *Registry) ForInstrument(instrumentID string) (*NormSet, bool) {
+	for _, name := range r.names {
+		if set := r.byName[name]; set.InstrumentID == instrumentID {
+			return set, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,207 @@
+// Package norms は規準集団に基づく標準得点（z得点・T得点・パーセンタイル）を計算します
+package norms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 得点帯のID（T得点で判定）
+const (
+	BandVeryLow  = "very_low"
+	BandLow      = "low"
+	BandAverage  = "average"
+	BandHigh     = "high"
+	BandVeryHigh = "very_high"
+)
+
+// Breakpoint は素点とパーセンタイルの対応です
+type Breakpoint struct {
+	Score      float64 `json:"score" yaml:"score"`
+	Percentile float64 `json:"percentile" yaml:"percentile"`
+}
+
+// DimensionNorm は1つの次元の規準値です
+type DimensionNorm struct {
+	Mean float64 `json:"mean" yaml:"mean"`
+	SD   float64 `json:"sd" yaml:"sd"`
+	N    int     `json:"n,omitempty" yaml:"n,omitempty"`
+	// Percentiles は素点の昇順に並んだパーセンタイル表です。
+	// 指定されている場合は正規分布の近似ではなくこの表から補間します
+	Percentiles []Breakpoint `json:"percentiles,omitempty" yaml:"percentiles,omitempty"`
+}
+
+// Group は属性（年代・性別）ごとの規準値です。空の属性はすべてに一致します
+type Group struct {
+	AgeGroup   string                   `json:"ageGroup,omitempty" yaml:"ageGroup,omitempty"`
+	Gender     string                   `json:"gender,omitempty" yaml:"gender,omitempty"`
+	Dimensions map[string]DimensionNorm `json:"dimensions" yaml:"dimensions"`
+}
+
+// NormSet は1つの規準集団の規準値の集合です
+type NormSet struct {
+	Name         string `json:"name" yaml:"name"`
+	InstrumentID string `json:"instrumentId" yaml:"instrumentId"`
+	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Dimensions は集団全体の規準値です
+	Dimensions map[string]DimensionNorm `json:"dimensions" yaml:"dimensions"`
+	Groups     []Group                  `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// validate は規準値の妥当性を検証します
+func (s *NormSet) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("norm set has no name")
+	}
+	check := func(where string, dims map[string]DimensionNorm) error {
+		for id, d := range dims {
+			if d.SD <= 0 {
+				return fmt.Errorf("norm set %s: %s: dimension %s has non-positive sd", s.Name, where, id)
+			}
+			for i := 1; i < len(d.Percentiles); i++ {
+				if d.Percentiles[i].Score < d.Percentiles[i-1].Score {
+					return fmt.Errorf("norm set %s: %s: percentiles of %s are not sorted by score", s.Name, where, id)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := check("overall", s.Dimensions); err != nil {
+		return err
+	}
+	for i, g := range s.Groups {
+		if err := check(fmt.Sprintf("group %d", i), g.Dimensions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup は属性に最も具体的に一致する規準値を返します。
+// 一致するグループに次元が定義されていない場合は集団全体の規準値を使います
+func (s *NormSet) Lookup(dimension, ageGroup, gender string) (DimensionNorm, bool) {
+	var best *Group
+	bestScore := -1
+	for i := range s.Groups {
+		g := &s.Groups[i]
+		if _, ok := g.Dimensions[dimension]; !ok {
+			continue
+		}
+		if (g.AgeGroup != "" && g.AgeGroup != ageGroup) || (g.Gender != "" && g.Gender != gender) {
+			continue
+		}
+		score := 0
+		if g.AgeGroup != "" {
+			score++
+		}
+		if g.Gender != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = g, score
+		}
+	}
+	if best != nil {
+		return best.Dimensions[dimension], true
+	}
+
+	norm, ok := s.Dimensions[dimension]
+	return norm, ok
+}
+
+// Score は1つの次元の標準得点です
+type Score struct {
+	Raw        float64 `json:"raw"`
+	Z          float64 `json:"z"`
+	T          float64 `json:"t"`
+	Percentile float64 `json:"percentile"`
+	Band       string  `json:"band"`
+	// Description は水準の説明です（API の応答時に言語に応じて設定されます）
+	Description string `json:"description,omitempty"`
+}
+
+// Convert は素点を標準得点に変換します
+func (d DimensionNorm) Convert(raw float64) Score {
+	z := (raw - d.Mean) / d.SD
+	t := 50 + 10*z
+
+	percentile := 100 * 0.5 * (1 + math.Erf(z/math.Sqrt2))
+	if len(d.Percentiles) > 0 {
+		percentile = interpolate(d.Percentiles, raw)
+	}
+
+	return Score{
+		Raw:        raw,
+		Z:          round(z, 2),
+		T:          round(t, 1),
+		Percentile: round(percentile, 1),
+		Band:       BandOf(t),
+	}
+}
+
+// BandOf はT得点が属する得点帯を返します
+func BandOf(t float64) string {
+	switch {
+	case t < 35:
+		return BandVeryLow
+	case t < 45:
+		return BandLow
+	case t < 55:
+		return BandAverage
+	case t < 65:
+		return BandHigh
+	default:
+		return BandVeryHigh
+	}
+}
+
+// interpolate はパーセンタイル表から素点に対応するパーセンタイルを線形補間します
+func interpolate(table []Breakpoint, raw float64) float64 {
+	i := sort.Search(len(table), func(i int) bool { return table[i].Score >= raw })
+	switch {
+	case i == 0:
+		return table[0].Percentile
+	case i == len(table):
+		return table[len(table)-1].Percentile
+	}
+
+	lo, hi := table[i-1], table[i]
+	if hi.Score == lo.Score {
+		return hi.Percentile
+	}
+	return lo.Percentile + (hi.Percentile-lo.Percentile)*(raw-lo.Score)/(hi.Score-lo.Score)
+}
+
+// round は小数点以下 digits 桁に丸めます
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
+
+// Profile は1回の診断結果の規準参照得点です
+type Profile struct {
+	NormSet    string           `json:"normSet"`
+	AgeGroup   string           `json:"ageGroup,omitempty"`
+	Gender     string           `json:"gender,omitempty"`
+	Dimensions map[string]Score `json:"dimensions"`
+}
+
+// Apply は各次元の素点を規準参照得点に変換します。規準値のない次元は含まれません
+func (s *NormSet) Apply(raw map[string]float64, ageGroup, gender string) *Profile {
+	profile := &Profile{
+		NormSet:    s.Name,
+		AgeGroup:   ageGroup,
+		Gender:     gender,
+		Dimensions: make(map[string]Score, len(raw)),
+	}
+	for dimension, score := range raw {
+		norm, ok := s.Lookup(dimension, ageGroup, gender)
+		if !ok {
+			continue
+		}
+		profile.Dimensions[dimension] = norm.Convert(score)
+	}
+	return profile
+}
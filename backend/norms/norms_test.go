@@ -0,0 +1,170 @@
+package norms
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestConvertNormal(t *testing.T) {
+	norm := DimensionNorm{Mean: 3.0, SD: 0.5}
+
+	tests := []struct {
+		raw        float64
+		z          float64
+		t          float64
+		percentile float64
+		band       string
+	}{
+		{raw: 3.0, z: 0, t: 50, percentile: 50, band: BandAverage},
+		{raw: 3.5, z: 1, t: 60, percentile: 84.1, band: BandHigh},
+		{raw: 2.0, z: -2, t: 30, percentile: 2.3, band: BandVeryLow},
+		{raw: 2.6, z: -0.8, t: 42, percentile: 21.2, band: BandLow},
+		{raw: 4.0, z: 2, t: 70, percentile: 97.7, band: BandVeryHigh},
+	}
+
+	for _, tt := range tests {
+		got := norm.Convert(tt.raw)
+		if !almostEqual(got.Z, tt.z, 0.01) || !almostEqual(got.T, tt.t, 0.1) ||
+			!almostEqual(got.Percentile, tt.percentile, 0.1) || got.Band != tt.band {
+			t.Errorf("Convert(%f) = %+v, expected z=%f t=%f percentile=%f band=%s",
+				tt.raw, got, tt.z, tt.t, tt.percentile, tt.band)
+		}
+	}
+}
+
+func TestConvertPercentileTable(t *testing.T) {
+	norm := DimensionNorm{
+		Mean: 3.0,
+		SD:   0.5,
+		Percentiles: []Breakpoint{
+			{Score: 2.0, Percentile: 10},
+			{Score: 3.0, Percentile: 40},
+			{Score: 4.0, Percentile: 90},
+		},
+	}
+
+	tests := []struct {
+		raw      float64
+		expected float64
+	}{
+		{raw: 1.0, expected: 10}, // 表の範囲外（下限）
+		{raw: 2.5, expected: 25},
+		{raw: 3.0, expected: 40},
+		{raw: 3.5, expected: 65},
+		{raw: 5.0, expected: 90}, // 表の範囲外（上限）
+	}
+
+	for _, tt := range tests {
+		if got := norm.Convert(tt.raw).Percentile; !almostEqual(got, tt.expected, 0.01) {
+			t.Errorf("Expected percentile %f for raw %f, got %f", tt.expected, tt.raw, got)
+		}
+	}
+}
+
+func TestLookupGroups(t *testing.T) {
+	set := &NormSet{
+		Name:       "test",
+		Dimensions: map[string]DimensionNorm{"openness": {Mean: 3.0, SD: 1}, "neuroticism": {Mean: 2.5, SD: 1}},
+		Groups: []Group{
+			{AgeGroup: "20s", Dimensions: map[string]DimensionNorm{"openness": {Mean: 3.2, SD: 1}}},
+			{AgeGroup: "20s", Gender: "female", Dimensions: map[string]DimensionNorm{"openness": {Mean: 3.4, SD: 1}}},
+			{Gender: "male", Dimensions: map[string]DimensionNorm{"openness": {Mean: 2.8, SD: 1}}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		dimension string
+		ageGroup  string
+		gender    string
+		mean      float64
+	}{
+		{name: "年代と性別が一致", dimension: "openness", ageGroup: "20s", gender: "female", mean: 3.4},
+		{name: "年代のみ一致", dimension: "openness", ageGroup: "20s", gender: "male", mean: 3.2},
+		{name: "性別のみ一致", dimension: "openness", ageGroup: "40s", gender: "male", mean: 2.8},
+		{name: "属性なし", dimension: "openness", mean: 3.0},
+		{name: "グループに次元がない", dimension: "neuroticism", ageGroup: "20s", gender: "female", mean: 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			norm, ok := set.Lookup(tt.dimension, tt.ageGroup, tt.gender)
+			if !ok || norm.Mean != tt.mean {
+				t.Errorf("Expected mean %f, got %+v (ok=%v)", tt.mean, norm, ok)
+			}
+		})
+	}
+
+	if _, ok := set.Lookup("extraversion", "", ""); ok {
+		t.Error("Expected no norm for undefined dimension")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"general.yaml": `
+name: general
+instrumentId: hpcs-74
+dimensions:
+  openness: {mean: 3.0, sd: 0.6}
+`,
+		"staff.json": `{"name":"staff","instrumentId":"hpcs-74","dimensions":{"openness":{"mean":3.3,"sd":0.5}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sets, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to load norms: %v", err)
+	}
+	registry, err := NewRegistry(sets)
+	if err != nil {
+		t.Fatalf("Failed to create registry: %v", err)
+	}
+
+	if set, ok := registry.ForInstrument("hpcs-74"); !ok || set.Name != "general" {
+		t.Errorf("Expected general as default norm set, got %+v", set)
+	}
+	if _, ok := registry.Get("staff"); !ok {
+		t.Error("Expected staff norm set to be loaded")
+	}
+	if _, ok := registry.ForInstrument("short-4"); ok {
+		t.Error("Expected no norm set for short-4")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "名前なし", input: `{"dimensions":{}}`, expected: "norm set has no name"},
+		{name: "標準偏差が0", input: `{"name":"x","dimensions":{"openness":{"mean":3,"sd":0}}}`, expected: "non-positive sd"},
+		{
+			name:     "パーセンタイル表の順序",
+			input:    `{"name":"x","dimensions":{"openness":{"mean":3,"sd":1,"percentiles":[{"score":3,"percentile":50},{"score":2,"percentile":60}]}}}`,
+			expected: "not sorted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input), "json")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing '%s', got %v", tt.expected, err)
+			}
+		})
+	}
+}
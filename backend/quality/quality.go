@@ -0,0 +1,162 @@
+// Package quality は不注意回答（同じ選択肢の連続、ばらつきの欠如、矛盾した回答、速すぎる回答）を検出します
+package quality
+
+import (
+	"hpcs/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// 検出フラグ
+const (
+	FlagLongstring     = "longstring"
+	FlagLowVariability = "low_variability"
+	FlagInconsistent   = "inconsistent"
+	FlagTooFast        = "too_fast"
+)
+
+// 閾値の既定値（1〜5段階の尺度を想定）
+const (
+	DefaultMaxLongstring     = 15
+	DefaultMinIRV            = 0.5
+	DefaultMaxInconsistency  = 1.5
+	DefaultMinSecondsPerItem = 2.0
+)
+
+// Report は1回分の回答の品質指標です
+type Report struct {
+	// LongestRun は質問順に並べたときに同じ回答が連続した最大の長さです
+	LongestRun int `json:"longestRun"`
+	// IRV は回答者内の回答のばらつき（標準偏差）です
+	IRV float64 `json:"irv"`
+	// Inconsistency は組になった質問の得点差の絶対値の平均です（評価できる組がない場合は nil）
+	Inconsistency *float64 `json:"inconsistency"`
+	// CompletionSeconds は回答にかかった秒数です（不明な場合は nil）
+	CompletionSeconds *float64 `json:"completionSeconds"`
+	Flags             []string `json:"flags"`
+	// Flagged はいずれかのフラグが立っていることを表します
+	Flagged bool `json:"flagged"`
+}
+
+// Check は回答の品質を評価します。duration が 0 の場合は回答時間を評価しません
+func Check(inst *models.Instrument, responses []models.Response, duration time.Duration) Report {
+	rules := inst.Quality
+	if rules.MaxLongstring == 0 {
+		rules.MaxLongstring = DefaultMaxLongstring
+	}
+	if rules.MinIRV == 0 {
+		rules.MinIRV = DefaultMinIRV
+	}
+	if rules.MaxInconsistency == 0 {
+		rules.MaxInconsistency = DefaultMaxInconsistency
+	}
+	if rules.MinSecondsPerItem == 0 {
+		rules.MinSecondsPerItem = DefaultMinSecondsPerItem
+	}
+
+	report := Report{Flags: []string{}}
+	if len(responses) == 0 {
+		return report
+	}
+
+	sorted := make([]models.Response, len(responses))
+	copy(sorted, responses)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].QuestionID < sorted[j].QuestionID })
+
+	report.LongestRun = longestRun(sorted)
+	if report.LongestRun > rules.MaxLongstring {
+		report.Flags = append(report.Flags, FlagLongstring)
+	}
+
+	// 1問だけの回答ではばらつきを評価しない
+	if len(sorted) > 1 {
+		report.IRV = round(irv(sorted), 3)
+		if report.IRV < rules.MinIRV {
+			report.Flags = append(report.Flags, FlagLowVariability)
+		}
+	}
+
+	if v, ok := inconsistency(inst, sorted, rules.Pairs); ok {
+		v = round(v, 3)
+		report.Inconsistency = &v
+		if v > rules.MaxInconsistency {
+			report.Flags = append(report.Flags, FlagInconsistent)
+		}
+	}
+
+	if duration > 0 {
+		seconds := round(duration.Seconds(), 1)
+		report.CompletionSeconds = &seconds
+		if seconds/float64(len(sorted)) < rules.MinSecondsPerItem {
+			report.Flags = append(report.Flags, FlagTooFast)
+		}
+	}
+
+	report.Flagged = len(report.Flags) > 0
+	return report
+}
+
+// longestRun は同じ回答が連続した最大の長さを求めます
+func longestRun(responses []models.Response) int {
+	longest, run := 1, 1
+	for i := 1; i < len(responses); i++ {
+		if responses[i].Score == responses[i-1].Score {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// irv は回答の標準偏差を求めます（逆転前の素の回答を使います）
+func irv(responses []models.Response) float64 {
+	var sum float64
+	for _, r := range responses {
+		sum += float64(r.Score)
+	}
+	mean := sum / float64(len(responses))
+
+	var ss float64
+	for _, r := range responses {
+		d := float64(r.Score) - mean
+		ss += d * d
+	}
+	return math.Sqrt(ss / float64(len(responses)))
+}
+
+// inconsistency は両方に回答された組の、逆転処理後の得点差の絶対値の平均を求めます
+func inconsistency(inst *models.Instrument, responses []models.Response, pairs [][2]int) (float64, bool) {
+	scores := make(map[int]float64, len(responses))
+	for _, r := range responses {
+		if q, ok := inst.Bank().Question(r.QuestionID); ok {
+			scores[r.QuestionID] = inst.KeyedScore(q, r.Score)
+		}
+	}
+
+	var total float64
+	var count int
+	for _, pair := range pairs {
+		a, okA := scores[pair[0]]
+		b, okB := scores[pair[1]]
+		if !okA || !okB {
+			continue
+		}
+		total += math.Abs(a - b)
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
+
+// round は小数点以下 digits 桁に丸めます
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,132 @@
+package quality
+
+import (
+	"hpcs/models"
+	"testing"
+	"time"
+)
+
+// testInstrument は6問と2組の対を持つ検査です
+func testInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	inst, err := models.ParseInstrument([]byte(`
+id: test
+scale: {min: 1, max: 5}
+dimensions: [{id: a}]
+quality:
+  pairs: [[1, 2], [3, 4]]
+  maxLongstring: 4
+items:
+  - {id: 1, category: a}
+  - {id: 2, category: a}
+  - {id: 3, category: a}
+  - {id: 4, category: a, isReverse: true}
+  - {id: 5, category: a}
+  - {id: 6, category: a}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	return inst
+}
+
+func responses(scores ...int) []models.Response {
+	var rs []models.Response
+	for i, s := range scores {
+		rs = append(rs, models.Response{QuestionID: i + 1, Score: s})
+	}
+	return rs
+}
+
+func TestCheck(t *testing.T) {
+	inst := testInstrument(t)
+
+	tests := []struct {
+		name          string
+		responses     []models.Response
+		duration      time.Duration
+		longestRun    int
+		inconsistency float64
+		flags         []string
+	}{
+		{
+			name:          "問題のない回答",
+			responses:     responses(4, 5, 2, 4, 1, 3),
+			duration:      time.Minute,
+			longestRun:    1,
+			inconsistency: 0.5, // |4-5| と |2-(6-4)| の平均
+			flags:         []string{},
+		},
+		{
+			name:          "すべて同じ回答",
+			responses:     responses(3, 3, 3, 3, 3, 3),
+			longestRun:    6,
+			inconsistency: 0,
+			flags:         []string{FlagLongstring, FlagLowVariability},
+		},
+		{
+			name:          "矛盾した回答",
+			responses:     responses(1, 5, 5, 5, 2, 4),
+			longestRun:    3,
+			inconsistency: 4, // |1-5| と |5-(6-5)| の平均
+			flags:         []string{FlagInconsistent},
+		},
+		{
+			name:          "速すぎる回答",
+			responses:     responses(4, 5, 2, 4, 1, 3),
+			duration:      6 * time.Second,
+			longestRun:    1,
+			inconsistency: 0.5,
+			flags:         []string{FlagTooFast},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Check(inst, tt.responses, tt.duration)
+			if report.LongestRun != tt.longestRun {
+				t.Errorf("Expected longest run %d, got %d", tt.longestRun, report.LongestRun)
+			}
+			if report.Inconsistency == nil || *report.Inconsistency != tt.inconsistency {
+				t.Errorf("Expected inconsistency %f, got %v", tt.inconsistency, report.Inconsistency)
+			}
+			if len(report.Flags) != len(tt.flags) {
+				t.Fatalf("Expected flags %v, got %v", tt.flags, report.Flags)
+			}
+			for i, flag := range tt.flags {
+				if report.Flags[i] != flag {
+					t.Errorf("Expected flags %v, got %v", tt.flags, report.Flags)
+				}
+			}
+			if report.Flagged != (len(tt.flags) > 0) {
+				t.Errorf("Expected flagged to be %v", len(tt.flags) > 0)
+			}
+			if (tt.duration > 0) != (report.CompletionSeconds != nil) {
+				t.Errorf("Unexpected completion seconds: %v", report.CompletionSeconds)
+			}
+		})
+	}
+}
+
+func TestCheckPartialResponses(t *testing.T) {
+	inst := testInstrument(t)
+
+	// 対の片方しか回答がない場合は矛盾を評価しない
+	report := Check(inst, []models.Response{{QuestionID: 1, Score: 2}, {QuestionID: 3, Score: 4}}, 0)
+	if report.Inconsistency != nil {
+		t.Errorf("Expected no inconsistency without complete pairs, got %v", *report.Inconsistency)
+	}
+	if !almostEqual(report.IRV, 1.0) {
+		t.Errorf("Expected IRV 1.0, got %f", report.IRV)
+	}
+
+	empty := Check(inst, nil, 0)
+	if empty.Flagged || empty.LongestRun != 0 {
+		t.Errorf("Expected empty report for no responses, got %+v", empty)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	d := a - b
+	return d < 0.001 && d > -0.001
+}
@@ -0,0 +1,313 @@
+// Package report は診断結果の PDF レポートを作成します
+package report
+
+import (
+	"fmt"
+	"hpcs/chart"
+	"hpcs/i18n"
+	"hpcs/interpret"
+	"hpcs/models"
+	"hpcs/norms"
+	"io"
+	"math"
+	"os"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ページのレイアウト（A4 縦、単位は mm）
+const (
+	pageWidth    = 210.0
+	margin       = 20.0
+	contentWidth = pageWidth - 2*margin
+	barWidth     = 110.0
+	barHeight    = 6.0
+)
+
+// fontFamily は UTF-8 フォントを登録する名前です
+const fontFamily = "report"
+
+// Options はレポートの作成方法です
+type Options struct {
+	// Lang はレポートの言語です（空の場合は検査定義の言語）
+	Lang string
+	// FontPath は日本語などを表示するための TrueType フォントのパスです。
+	// 指定がない場合は標準フォントを使うため、レポートは英語で作成されます
+	FontPath string
+}
+
+// Render は診断結果のレポートを PDF で書き出します。profile が nil の場合は規準参照得点を省略します
+func Render(w io.Writer, assessment *models.Assessment, inst *models.Instrument, profile *norms.Profile, opts Options) error {
+	lang := opts.Lang
+	if lang == "" {
+		lang = inst.Language
+	}
+	if opts.FontPath == "" {
+		lang = i18n.English
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.SetCreationDate(assessment.CreatedAt)
+
+	r := &renderer{
+		pdf:        pdf,
+		lang:       lang,
+		assessment: assessment,
+		inst:       inst.Localize(lang),
+		profile:    profile,
+		translate:  func(s string) string { return s },
+	}
+	if opts.FontPath != "" {
+		font, err := os.ReadFile(opts.FontPath)
+		if err != nil {
+			return fmt.Errorf("failed to load report font: %w", err)
+		}
+		pdf.AddUTF8FontFromBytes(fontFamily, "", font)
+		r.family = fontFamily
+	} else {
+		r.family = "Helvetica"
+		r.translate = pdf.UnicodeTranslatorFromDescriptor("")
+	}
+	pdf.SetTitle(r.text("report.title"), true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margin + 5)
+		r.font(9)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 5, r.translate(i18n.T(lang, "report.page", pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	r.summaryPage()
+	r.scoresPage()
+	r.interpretationPage()
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+// renderer はレポートの各ページを描画します
+type renderer struct {
+	pdf        *fpdf.Fpdf
+	lang       string
+	family     string
+	assessment *models.Assessment
+	inst       *models.Instrument
+	profile    *norms.Profile
+	// translate は標準フォントで表示できる文字コードに変換します
+	translate func(string) string
+}
+
+// text はカタログのメッセージを表示用に変換して返します
+func (r *renderer) text(key string, args ...any) string {
+	return r.translate(i18n.T(r.lang, key, args...))
+}
+
+// font はフォントの大きさを設定します
+func (r *renderer) font(size float64) {
+	r.pdf.SetFont(r.family, "", size)
+}
+
+// heading はページの見出しを描画します
+func (r *renderer) heading(key string) {
+	r.pdf.SetTextColor(0, 0, 0)
+	r.font(16)
+	r.pdf.CellFormat(0, 10, r.text(key), "", 1, "L", false, 0, "")
+	r.pdf.Ln(4)
+}
+
+// position は尺度上のスコアの位置を 0〜1 で返します
+func (r *renderer) position(score float64) float64 {
+	scale := r.inst.Scale
+	return (score - float64(scale.Min)) / float64(scale.Max-scale.Min)
+}
+
+// summaryPage は実施日、検査とプロフィールのレーダーチャートを描画します
+func (r *renderer) summaryPage() {
+	pdf := r.pdf
+	pdf.AddPage()
+
+	r.font(20)
+	pdf.CellFormat(0, 12, r.text("report.title"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	rows := [][2]string{
+		{r.text("report.date"), r.assessment.CreatedAt.Format("2006-01-02")},
+		{r.text("report.instrument"), r.translate(fmt.Sprintf("%s (%s)", r.inst.Name, r.inst.Version))},
+		{r.text("report.result_id"), r.assessment.ID},
+	}
+	if r.profile != nil {
+		rows = append(rows, [2]string{r.text("report.norm_set"), r.translate(r.profile.NormSet)})
+	}
+	r.font(11)
+	for _, row := range rows {
+		pdf.CellFormat(40, 7, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	r.heading("report.profile")
+	r.radar(pageWidth/2, pdf.GetY()+75, 60)
+}
+
+// radar は次元ごとのスコアのレーダーチャートを描画します。算出されなかった次元は最小値として描きます
+func (r *renderer) radar(cx, cy, radius float64) {
+	pdf := r.pdf
+	n := len(r.inst.Dimensions)
+	if n < 3 {
+		return
+	}
+	point := func(i int, length float64) fpdf.PointType {
+		x, y := chart.Vertex(i, n, cx, cy, length)
+		return fpdf.PointType{X: x, Y: y}
+	}
+
+	// 目盛り（尺度の各段階）と軸
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(200, 200, 200)
+	steps := r.inst.Scale.Max - r.inst.Scale.Min
+	for step := 1; step <= steps; step++ {
+		ring := make([]fpdf.PointType, n)
+		for i := range ring {
+			ring[i] = point(i, radius*float64(step)/float64(steps))
+		}
+		pdf.Polygon(ring, "D")
+	}
+	for i := 0; i < n; i++ {
+		p := point(i, radius)
+		pdf.Line(cx, cy, p.X, p.Y)
+	}
+
+	// スコア
+	scores := make([]fpdf.PointType, n)
+	for i, d := range r.inst.Dimensions {
+		length := 0.0
+		if score, ok := r.assessment.Result.Get(d.ID); ok {
+			length = radius * r.position(score)
+		}
+		scores[i] = point(i, length)
+	}
+	pdf.SetAlpha(0.35, "Normal")
+	pdf.SetFillColor(79, 70, 229)
+	pdf.Polygon(scores, "F")
+	pdf.SetAlpha(1, "Normal")
+	pdf.SetLineWidth(0.6)
+	pdf.SetDrawColor(79, 70, 229)
+	pdf.Polygon(scores, "D")
+
+	// 次元名
+	r.font(10)
+	pdf.SetTextColor(0, 0, 0)
+	for i, d := range r.inst.Dimensions {
+		label := r.translate(d.Name)
+		p := point(i, radius+6)
+		width := pdf.GetStringWidth(label)
+		x := p.X - width/2
+		if p.X > cx+1 {
+			x = p.X
+		} else if p.X < cx-1 {
+			x = p.X - width
+		}
+		pdf.Text(x, p.Y+1.5, label)
+	}
+}
+
+// scoresPage は次元ごとのスコアを棒グラフで描画します
+func (r *renderer) scoresPage() {
+	pdf := r.pdf
+	pdf.AddPage()
+	r.heading("report.scores")
+
+	for _, d := range r.inst.Dimensions {
+		r.font(12)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(0, 8, r.translate(d.Name), "", 1, "L", false, 0, "")
+
+		score, ok := r.assessment.Result.Get(d.ID)
+		if !ok {
+			r.font(10)
+			pdf.SetTextColor(128, 128, 128)
+			pdf.CellFormat(0, 6, r.text("report.not_scored"), "", 1, "L", false, 0, "")
+			pdf.Ln(4)
+			continue
+		}
+		r.bar(score, 79, 70, 229)
+
+		// 下位側面
+		for _, f := range d.Facets {
+			facetScore, ok := r.assessment.Result.Facets[d.ID][f.ID]
+			if !ok {
+				continue
+			}
+			r.font(9)
+			pdf.SetTextColor(80, 80, 80)
+			pdf.CellFormat(0, 5, r.translate(f.Name), "", 1, "L", false, 0, "")
+			r.bar(facetScore, 165, 160, 240)
+		}
+
+		if s, ok := r.profileScore(d.ID); ok {
+			r.font(9)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.CellFormat(0, 5, r.text("report.norm_score", s.Percentile, s.T), "", 1, "L", false, 0, "")
+			pdf.MultiCell(contentWidth, 5, r.text("band."+s.Band), "", "L", false)
+		}
+		pdf.Ln(4)
+	}
+}
+
+// bar はスコアの棒と値を1行で描画します
+func (r *renderer) bar(score float64, red, green, blue int) {
+	pdf := r.pdf
+	x, y := pdf.GetX(), pdf.GetY()
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.Rect(x, y, barWidth, barHeight, "FD")
+	pdf.SetFillColor(red, green, blue)
+	pdf.Rect(x, y, barWidth*math.Max(0, math.Min(1, r.position(score))), barHeight, "F")
+
+	r.font(10)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetX(x + barWidth + 4)
+	pdf.CellFormat(0, barHeight, fmt.Sprintf("%.2f / %d", score, r.inst.Scale.Max), "", 1, "L", false, 0, "")
+	pdf.Ln(1)
+}
+
+// profileScore は次元の規準参照得点を返します
+func (r *renderer) profileScore(dimension string) (norms.Score, bool) {
+	if r.profile == nil {
+		return norms.Score{}, false
+	}
+	s, ok := r.profile.Dimensions[dimension]
+	return s, ok
+}
+
+// interpretationPage は解釈文を描画します
+func (r *renderer) interpretationPage() {
+	pdf := r.pdf
+	interpretation := interpret.Interpret(r.inst, r.assessment.Result, r.profile, r.lang)
+
+	pdf.AddPage()
+	r.heading("report.interpretation")
+	for _, p := range interpretation.Dimensions {
+		d, _ := r.inst.Dimension(p.Dimension)
+		r.font(12)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(0, 8, r.translate(d.Name), "", 1, "L", false, 0, "")
+		r.font(10)
+		pdf.MultiCell(contentWidth, 5.5, r.translate(p.Text), "", "L", false)
+		pdf.Ln(3)
+	}
+
+	if len(interpretation.Combinations) == 0 {
+		return
+	}
+	pdf.Ln(4)
+	r.heading("report.combinations")
+	r.font(10)
+	for _, p := range interpretation.Combinations {
+		pdf.MultiCell(contentWidth, 5.5, r.translate(p.Text), "", "L", false)
+		pdf.Ln(3)
+	}
+}
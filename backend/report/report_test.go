@@ -0,0 +1,86 @@
+package report
+
+import (
+	"bytes"
+	"hpcs/data"
+	"hpcs/models"
+	"hpcs/norms"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func testAssessment() *models.Assessment {
+	return &models.Assessment{
+		ID:                "abc123",
+		InstrumentID:      "hpcs-74",
+		InstrumentVersion: "1.2.0",
+		Result: models.Result{
+			Neuroticism:       models.Float64(2.25),
+			Extraversion:      models.Float64(4.5),
+			Conscientiousness: models.Float64(3.5),
+			Openness:          models.Float64(3.0),
+			Facets: map[string]map[string]float64{
+				"extraversion": {"sociability": 4.5},
+			},
+		},
+		CreatedAt: time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+// pageCount は PDF のページ数を数えます
+func pageCount(pdf []byte) int {
+	return len(regexp.MustCompile(`/Type /Page\b`).FindAll(pdf, -1))
+}
+
+func TestRender(t *testing.T) {
+	set, err := data.LoadInstrumentSet("", "")
+	if err != nil {
+		t.Fatalf("Failed to load instruments: %v", err)
+	}
+	inst := set.Default()
+
+	profile := &norms.Profile{
+		NormSet: "general",
+		Dimensions: map[string]norms.Score{
+			"extraversion": {Raw: 4.5, Z: 1.5, T: 65, Percentile: 93.3, Band: norms.BandVeryHigh},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		profile *norms.Profile
+		lang    string
+	}{
+		{name: "規準値なし", profile: nil},
+		{name: "規準値あり", profile: profile},
+		// フォントが指定されていない場合は英語で作成される
+		{name: "日本語の指定", profile: profile, lang: "ja"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, testAssessment(), inst, tt.profile, Options{Lang: tt.lang}); err != nil {
+				t.Fatalf("Failed to render report: %v", err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+				t.Fatalf("Expected PDF output, got %q", buf.Bytes()[:16])
+			}
+			if got := pageCount(buf.Bytes()); got != 3 {
+				t.Errorf("Expected 3 pages, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRenderMissingFont(t *testing.T) {
+	set, _ := data.LoadInstrumentSet("", "")
+
+	var buf bytes.Buffer
+	err := Render(&buf, testAssessment(), set.Default(), nil, Options{FontPath: filepath.Join(t.TempDir(), "missing.ttf")})
+	if err == nil {
+		t.Error("Expected error for missing font")
+	}
+}
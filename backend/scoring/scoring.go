@@ -0,0 +1,205 @@
+// Package scoring は回答の検証、次元と下位側面のスコア計算、規準参照得点と回答品質の評価を行います。
+// HTTP に依存しないため、API のハンドラーと CLI のほか、他のサービスからも同じ採点処理を使えます
+package scoring
+
+import (
+	"fmt"
+	"hpcs/models"
+	"hpcs/norms"
+	"hpcs/quality"
+	"hpcs/validation"
+	"time"
+)
+
+// Submission は採点する1人分の回答です
+type Submission struct {
+	Responses    []models.Response
+	Demographics *models.Demographics
+	// NormSet は規準参照得点の計算に使う規準値です（nil の場合は計算しません）
+	NormSet *norms.NormSet
+	// Duration は回答にかかった時間です（不明な場合は 0）
+	Duration time.Duration
+}
+
+// Outcome は採点の結果です
+type Outcome struct {
+	InstrumentID      string `json:"instrumentId"`
+	InstrumentVersion string `json:"instrumentVersion"`
+	// Result は次元と下位側面のスコアと次元ごとの回答状況です
+	Result models.Result `json:"result"`
+	// Norms は規準参照得点です（規準値を指定しなかった場合は nil）
+	Norms *norms.Profile `json:"norms,omitempty"`
+	// NormSet は規準参照得点の計算に使った規準値の名前です
+	NormSet string         `json:"normSet,omitempty"`
+	Quality quality.Report `json:"quality"`
+}
+
+// Scorer は1つの検査の定義に従って回答を検証し、採点します。
+// 作成後は変更されないため、複数の goroutine から同時に使えます
+type Scorer struct {
+	inst *models.Instrument
+	// questions は次元IDごとの、次元に属する質問の索引です
+	questions map[string]map[int]models.Question
+}
+
+// New は検査の定義から Scorer を作成します
+func New(inst *models.Instrument) *Scorer {
+	s := &Scorer{inst: inst, questions: make(map[string]map[int]models.Question, len(inst.Dimensions))}
+	for _, dimension := range inst.DimensionIDs() {
+		questions := make(map[int]models.Question)
+		for _, q := range inst.Bank().ByCategory(dimension) {
+			questions[q.ID] = q
+		}
+		s.questions[dimension] = questions
+	}
+	return s
+}
+
+// Instrument は採点に使う検査の定義を返します
+func (s *Scorer) Instrument() *models.Instrument {
+	return s.inst
+}
+
+// Validate は回答データのバリデーションを行います。
+// 問題がある場合は見つかったすべての問題を validation.Errors として返します
+func (s *Scorer) Validate(responses []models.Response) error {
+	if errs := validation.Responses(s.inst, responses); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Score は回答を検証して採点し、規準参照得点と回答品質を評価します。
+// 回答に問題がある場合は validation.Errors を、規準値が別の検査のものである場合はエラーを返します
+func (s *Scorer) Score(sub Submission) (Outcome, error) {
+	if err := s.Validate(sub.Responses); err != nil {
+		return Outcome{}, err
+	}
+	if sub.NormSet != nil && sub.NormSet.InstrumentID != s.inst.ID {
+		return Outcome{}, fmt.Errorf("norm set %s is not defined for instrument %s", sub.NormSet.Name, s.inst.ID)
+	}
+
+	result := s.Result(sub.Responses)
+	outcome := Outcome{
+		InstrumentID:      s.inst.ID,
+		InstrumentVersion: s.inst.Version,
+		Result:            result,
+		Norms:             s.Norms(sub.NormSet, result, sub.Demographics),
+		Quality:           s.Quality(sub.Responses, sub.Duration),
+	}
+	if sub.NormSet != nil {
+		outcome.NormSet = sub.NormSet.Name
+	}
+	return outcome, nil
+}
+
+// Result は検査のすべての次元のスコアを計算します。回答の検証は行いません
+func (s *Scorer) Result(responses []models.Response) models.Result {
+	result := models.Result{Coverage: make(map[string]models.DimensionCoverage)}
+	for _, dimension := range s.inst.DimensionIDs() {
+		// 欠損値の扱いに従い、回答が不足する次元のスコアは nil とする
+		score, answered := s.dimensionMean(responses, dimension)
+		coverage := models.DimensionCoverage{
+			Answered: answered,
+			Expected: len(s.questions[dimension]),
+		}
+		coverage.Sufficient = s.inst.Missing.Sufficient(coverage.Answered, coverage.Expected)
+		result.Coverage[dimension] = coverage
+
+		if coverage.Sufficient {
+			result.Set(dimension, models.Float64(score))
+		} else {
+			result.Set(dimension, nil)
+		}
+
+		if facets := s.FacetScores(responses, dimension); len(facets) > 0 {
+			if result.Facets == nil {
+				result.Facets = make(map[string]map[string]float64)
+			}
+			result.Facets[dimension] = facets
+		}
+	}
+	return result
+}
+
+// Norms は診断結果を規準参照得点に変換します。set が nil の場合は nil を返します
+func (s *Scorer) Norms(set *norms.NormSet, result models.Result, demographics *models.Demographics) *norms.Profile {
+	if set == nil {
+		return nil
+	}
+
+	raw := make(map[string]float64, len(s.inst.Dimensions))
+	for _, dimension := range s.inst.DimensionIDs() {
+		if score, ok := result.Get(dimension); ok {
+			raw[dimension] = score
+		}
+	}
+
+	var ageGroup, gender string
+	if demographics != nil {
+		ageGroup, gender = demographics.AgeGroup, demographics.Gender
+	}
+	return set.Apply(raw, ageGroup, gender)
+}
+
+// Quality は回答の品質指標を計算し、不注意回答を検出します。duration が 0 の場合は回答時間を評価しません
+func (s *Scorer) Quality(responses []models.Response, duration time.Duration) quality.Report {
+	return quality.Check(s.inst, responses, duration)
+}
+
+// DimensionScore は各次元のスコアを計算します。回答がない場合は 0 を返します
+func (s *Scorer) DimensionScore(responses []models.Response, dimension string) float64 {
+	score, _ := s.dimensionMean(responses, dimension)
+	return score
+}
+
+// dimensionMean は次元に属する回答済み項目の平均スコアと回答数を返します
+func (s *Scorer) dimensionMean(responses []models.Response, dimension string) (float64, int) {
+	// 各次元に属する質問のIDと逆転項目の情報を取得
+	questions := s.questions[dimension]
+
+	var totalScore float64
+	var count int
+
+	for _, response := range responses {
+		// この次元に属する質問かチェック
+		question, exists := questions[response.QuestionID]
+		if !exists {
+			continue
+		}
+
+		// 逆転項目の場合は検査の採点ルールに従ってスコアを反転
+		totalScore += s.inst.KeyedScore(question, response.Score)
+		count++
+	}
+
+	if count == 0 {
+		return 0, 0
+	}
+
+	// 平均スコアを計算して返す
+	return totalScore / float64(count), count
+}
+
+// FacetScores は次元の下位側面ごとの平均スコアを計算します。
+// 回答のない下位側面は含まれません
+func (s *Scorer) FacetScores(responses []models.Response, dimension string) map[string]float64 {
+	questions := s.questions[dimension]
+
+	totals := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, response := range responses {
+		question, exists := questions[response.QuestionID]
+		if !exists || question.Facet == "" {
+			continue
+		}
+		totals[question.Facet] += s.inst.KeyedScore(question, response.Score)
+		counts[question.Facet]++
+	}
+
+	facets := make(map[string]float64, len(counts))
+	for facet, count := range counts {
+		facets[facet] = totals[facet] / float64(count)
+	}
+	return facets
+}
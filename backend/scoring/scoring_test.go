@@ -0,0 +1,251 @@
+package scoring
+
+import (
+	"errors"
+	"hpcs/data"
+	"hpcs/models"
+	"hpcs/norms"
+	"hpcs/validation"
+	"testing"
+)
+
+// defaultInstrument は同梱の標準検査を返します
+func defaultInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	set, err := data.LoadInstrumentSet("", "")
+	if err != nil {
+		t.Fatalf("Failed to load instruments: %v", err)
+	}
+	return set.Default()
+}
+
+func TestDimensionScore(t *testing.T) {
+	scorer := New(defaultInstrument(t))
+
+	// テストケース1: 神経症傾向（通常項目と逆転項目を含む）
+	responses := []models.Response{
+		{QuestionID: 1, Score: 5},  // 通常項目
+		{QuestionID: 29, Score: 2}, // 逆転項目（実際のスコアは4）
+	}
+
+	result := scorer.DimensionScore(responses, "neuroticism")
+	expected := 4.5 // (5 + 4) / 2
+
+	if !almostEqual(result, expected, 0.01) {
+		t.Errorf("Expected neuroticism score to be %f, but got %f", expected, result)
+	}
+
+	// テストケース2: 外向性（通常項目のみ）
+	responses = []models.Response{
+		{QuestionID: 5, Score: 4},
+		{QuestionID: 6, Score: 5},
+	}
+
+	result = scorer.DimensionScore(responses, "extraversion")
+	expected = 4.5 // (4 + 5) / 2
+
+	if !almostEqual(result, expected, 0.01) {
+		t.Errorf("Expected extraversion score to be %f, but got %f", expected, result)
+	}
+
+	// テストケース3: 該当する回答がない場合
+	responses = []models.Response{
+		{QuestionID: 999, Score: 3}, // 存在しない質問ID
+	}
+
+	result = scorer.DimensionScore(responses, "openness")
+	expected = 0.0
+
+	if result != expected {
+		t.Errorf("Expected score to be %f when no matching responses, but got %f", expected, result)
+	}
+
+	// テストケース4: 複数の回答がある場合
+	responses = []models.Response{
+		{QuestionID: 13, Score: 4}, // 協調性
+		{QuestionID: 14, Score: 5},
+		{QuestionID: 15, Score: 3},
+	}
+
+	result = scorer.DimensionScore(responses, "agreeableness")
+	expected = 4.0 // (4 + 5 + 3) / 3
+
+	if !almostEqual(result, expected, 0.01) {
+		t.Errorf("Expected agreeableness score to be %f, but got %f", expected, result)
+	}
+}
+
+// 浮動小数点数の比較用ヘルパー関数
+func almostEqual(a, b, tolerance float64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
+
+func TestFacetScores(t *testing.T) {
+	scorer := New(defaultInstrument(t))
+
+	responses := []models.Response{
+		{QuestionID: 1, Score: 5},  // 不安
+		{QuestionID: 2, Score: 3},  // 不安
+		{QuestionID: 29, Score: 2}, // ストレス脆弱性（逆転項目、実際のスコアは4）
+		{QuestionID: 5, Score: 4},  // 外向性（対象外）
+	}
+
+	facets := scorer.FacetScores(responses, "neuroticism")
+	if len(facets) != 2 {
+		t.Fatalf("Expected 2 facets with answers, got %v", facets)
+	}
+	if !almostEqual(facets["anxiety"], 4.0, 0.01) {
+		t.Errorf("Expected anxiety score to be 4.0, got %f", facets["anxiety"])
+	}
+	if !almostEqual(facets["stress_vulnerability"], 4.0, 0.01) {
+		t.Errorf("Expected stress_vulnerability score to be 4.0, got %f", facets["stress_vulnerability"])
+	}
+
+	// 結果には次元ごとに下位側面のスコアが含まれる
+	result := scorer.Result(responses)
+	if !almostEqual(result.Facets["extraversion"]["sociability"], 4.0, 0.01) {
+		t.Errorf("Expected sociability score to be 4.0, got %v", result.Facets["extraversion"])
+	}
+	if _, exists := result.Facets["openness"]; exists {
+		t.Error("Expected dimensions without answers to have no facets")
+	}
+}
+
+func TestResultMissingPolicy(t *testing.T) {
+	parse := func(missing string) *models.Instrument {
+		inst, err := models.ParseInstrument([]byte(`
+id: missing
+scale: {min: 1, max: 5}
+`+missing+`
+dimensions: [{id: neuroticism}, {id: openness}]
+items:
+  - {id: 1, category: neuroticism}
+  - {id: 2, category: neuroticism}
+  - {id: 3, category: neuroticism}
+  - {id: 4, category: neuroticism}
+  - {id: 5, category: openness}
+`), "yaml")
+		if err != nil {
+			t.Fatalf("Failed to parse instrument: %v", err)
+		}
+		return inst
+	}
+
+	// 神経症傾向は4問中2問に回答、開放性は未回答
+	responses := []models.Response{
+		{QuestionID: 1, Score: 5},
+		{QuestionID: 2, Score: 3},
+	}
+
+	tests := []struct {
+		name        string
+		missing     string
+		neuroticism *float64
+	}{
+		{name: "既定（1問以上で平均）", missing: "", neuroticism: models.Float64(4)},
+		{name: "回答率50%以上で算出", missing: "missing: {method: prorate, minAnsweredFraction: 0.5}", neuroticism: models.Float64(4)},
+		{name: "回答率75%未満は算出しない", missing: "missing: {method: prorate, minAnsweredFraction: 0.75}", neuroticism: nil},
+		{name: "全問回答が必要", missing: "missing: {method: require_all}", neuroticism: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := New(parse(tt.missing)).Result(responses)
+
+			if tt.neuroticism == nil && result.Neuroticism != nil {
+				t.Errorf("Expected neuroticism to be insufficient, got %f", *result.Neuroticism)
+			}
+			if tt.neuroticism != nil && (result.Neuroticism == nil || !almostEqual(*result.Neuroticism, *tt.neuroticism, 0.01)) {
+				t.Errorf("Expected neuroticism score %f, got %v", *tt.neuroticism, result.Neuroticism)
+			}
+
+			coverage := result.Coverage["neuroticism"]
+			if coverage.Answered != 2 || coverage.Expected != 4 || coverage.Sufficient != (tt.neuroticism != nil) {
+				t.Errorf("Unexpected neuroticism coverage: %+v", coverage)
+			}
+
+			// 回答のない次元は 0 ではなく nil になる
+			if result.Openness != nil || result.Coverage["openness"].Sufficient {
+				t.Errorf("Expected openness to be insufficient, got %v", result.Openness)
+			}
+		})
+	}
+}
+
+func TestScorerScore(t *testing.T) {
+	scorer := New(defaultInstrument(t))
+	general := &norms.NormSet{
+		Name:         "general",
+		InstrumentID: "hpcs-74",
+		Dimensions:   map[string]norms.DimensionNorm{"neuroticism": {Mean: 3.0, SD: 0.5}},
+	}
+
+	tests := []struct {
+		name      string
+		sub       Submission
+		expectErr bool
+		// expectFields は回答の問題の件数です（validation.Errors でない場合は -1）
+		expectFields int
+	}{
+		{
+			name: "規準値を使った採点",
+			sub: Submission{
+				Responses: []models.Response{{QuestionID: 1, Score: 5}, {QuestionID: 2, Score: 5}, {QuestionID: 29, Score: 2}, {QuestionID: 30, Score: 2}},
+				NormSet:   general,
+			},
+		},
+		{
+			name:         "回答の問題をまとめて返す",
+			sub:          Submission{Responses: []models.Response{{QuestionID: 1, Score: 9}, {QuestionID: 999, Score: 3}}},
+			expectErr:    true,
+			expectFields: 2,
+		},
+		{
+			name: "別の検査の規準値",
+			sub: Submission{
+				Responses: []models.Response{{QuestionID: 1, Score: 5}},
+				NormSet:   &norms.NormSet{Name: "other", InstrumentID: "short-4"},
+			},
+			expectErr:    true,
+			expectFields: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, err := scorer.Score(tt.sub)
+			if tt.expectErr {
+				var fields validation.Errors
+				switch {
+				case err == nil:
+					t.Fatal("Expected error")
+				case tt.expectFields < 0 && errors.As(err, &fields):
+					t.Errorf("Expected a non-validation error, got %v", err)
+				case tt.expectFields >= 0 && (!errors.As(err, &fields) || len(fields) != tt.expectFields):
+					t.Errorf("Expected %d field errors, got %v", tt.expectFields, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if outcome.InstrumentID != "hpcs-74" || outcome.InstrumentVersion == "" || outcome.NormSet != "general" {
+				t.Errorf("Unexpected outcome metadata: %+v", outcome)
+			}
+			if outcome.Result.Neuroticism == nil || !almostEqual(*outcome.Result.Neuroticism, 4.5, 0.01) {
+				t.Errorf("Expected neuroticism score 4.5, got %v", outcome.Result.Neuroticism)
+			}
+			if outcome.Norms == nil || !almostEqual(outcome.Norms.Dimensions["neuroticism"].T, 80, 0.01) {
+				t.Errorf("Expected neuroticism T-score 80, got %+v", outcome.Norms)
+			}
+			if outcome.Quality.LongestRun != 2 || !almostEqual(outcome.Quality.IRV, 1.5, 0.01) {
+				t.Errorf("Unexpected quality report: %+v", outcome.Quality)
+			}
+		})
+	}
+}
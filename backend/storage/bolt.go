@@ -0,0 +1,809 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"hpcs/models"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	assessmentsBucket = []byte("assessments")
+	// assessmentTimesBucket は作成日時の順に診断結果をたどるための索引です
+	assessmentTimesBucket = []byte("assessment_times")
+	sessionsBucket        = []byte("sessions")
+	usersBucket           = []byte("users")
+	// userEmailsBucket はメールアドレスからユーザーIDを引く索引です
+	userEmailsBucket    = []byte("user_emails")
+	organizationsBucket = []byte("organizations")
+	teamsBucket         = []byte("teams")
+	membershipsBucket   = []byte("memberships")
+	campaignsBucket     = []byte("campaigns")
+	invitationsBucket   = []byte("invitations")
+)
+
+// BoltStore は bbolt を使った組み込みの Store 実装です
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBolt は指定したパスのデータベースを開きます（存在しない場合は作成します）
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			assessmentsBucket, assessmentTimesBucket, sessionsBucket, usersBucket, userEmailsBucket,
+			organizationsBucket, teamsBucket, membershipsBucket, campaignsBucket, invitationsBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return indexAssessmentTimes(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// SaveAssessment は診断結果を保存します
+func (s *BoltStore) SaveAssessment(a *models.Assessment) error {
+	return s.SaveAssessments([]*models.Assessment{a})
+}
+
+// SaveAssessments は複数の診断結果を1つのトランザクションでまとめて保存します。
+// 保存に失敗した場合はいずれの診断結果も保存されません
+func (s *BoltStore) SaveAssessments(assessments []*models.Assessment) error {
+	now := time.Now().UTC()
+	for _, a := range assessments {
+		if a.ID == "" {
+			a.ID = NewID()
+		}
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = now
+		}
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, a := range assessments {
+			if err := putAssessment(tx, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// putAssessment はトランザクション内で診断結果と作成日時の索引を保存します
+func putAssessment(tx *bolt.Tx, a *models.Assessment) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	bucket, times := tx.Bucket(assessmentsBucket), tx.Bucket(assessmentTimesBucket)
+	// 上書きする場合は以前の作成日時の索引を削除する
+	if old := bucket.Get([]byte(a.ID)); old != nil {
+		var previous models.Assessment
+		if err := json.Unmarshal(old, &previous); err != nil {
+			return err
+		}
+		if err := times.Delete(assessmentTimeKey(&previous)); err != nil {
+			return err
+		}
+	}
+	if err := times.Put(assessmentTimeKey(a), []byte(a.ID)); err != nil {
+		return err
+	}
+	return bucket.Put([]byte(a.ID), data)
+}
+
+// assessmentTimeKey は作成日時の索引のキーです。作成日時（UTC の固定長の文字列）の順に並びます
+func assessmentTimeKey(a *models.Assessment) []byte {
+	return []byte(a.CreatedAt.UTC().Format("20060102T150405.000000000") + "/" + a.ID)
+}
+
+// indexAssessmentTimes は作成日時の索引がない既存のデータベースに索引を作成します
+func indexAssessmentTimes(tx *bolt.Tx) error {
+	bucket, times := tx.Bucket(assessmentsBucket), tx.Bucket(assessmentTimesBucket)
+	if k, _ := times.Cursor().First(); k != nil {
+		return nil
+	}
+	return bucket.ForEach(func(id, data []byte) error {
+		var a models.Assessment
+		if err := json.Unmarshal(data, &a); err != nil {
+			return err
+		}
+		return times.Put(assessmentTimeKey(&a), id)
+	})
+}
+
+// assessmentPageSize は EachAssessment が1つの読み取りトランザクションで読む索引の件数です
+var assessmentPageSize = 200
+
+// EachAssessment は条件に一致する診断結果を古い順に読み込み、1件ずつ fn に渡します。
+// すべてを読み込んでから返す ListAssessments と異なり、assessmentPageSize 件ずつ短い読み取りトランザクションで読み込み、
+// fn はトランザクションの外で呼び出すため、fn の処理に時間がかかっても書き込みを妨げません。
+// filter.Limit は古い方から数えます。fn がエラーを返した場合はそこで中断し、そのエラーを返します
+func (s *BoltStore) EachAssessment(filter AssessmentFilter, fn func(a *models.Assessment) error) error {
+	var after []byte
+	count := 0
+	for {
+		page, last, err := s.assessmentPage(filter, after)
+		if err != nil {
+			return err
+		}
+		for i := range page {
+			if err := fn(&page[i]); err != nil {
+				return err
+			}
+			count++
+			if filter.Limit > 0 && count >= filter.Limit {
+				return nil
+			}
+		}
+		if last == nil {
+			return nil
+		}
+		after = last
+	}
+}
+
+// assessmentPage は作成日時の索引を after の次のキーから最大 assessmentPageSize 件読み、条件に一致する診断結果と
+// 最後に読んだキーを返します（after が nil の場合は最初から読みます）。索引の終わりまで読んだ場合、キーは nil になります
+func (s *BoltStore) assessmentPage(filter AssessmentFilter, after []byte) ([]models.Assessment, []byte, error) {
+	var page []models.Assessment
+	var last []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(assessmentsBucket)
+		cursor := tx.Bucket(assessmentTimesBucket).Cursor()
+		k, id := cursor.First()
+		if after != nil {
+			// 前のページの最後のキーは読み飛ばす（その後に削除されていれば Seek は次のキーを返す）
+			if k, id = cursor.Seek(after); k != nil && string(k) == string(after) {
+				k, id = cursor.Next()
+			}
+		}
+		for n := 0; k != nil && n < assessmentPageSize; n++ {
+			// キーはトランザクションの外では使えないためコピーする
+			last = append(last[:0], k...)
+			if data := bucket.Get(id); data != nil {
+				var a models.Assessment
+				if err := json.Unmarshal(data, &a); err != nil {
+					return err
+				}
+				if filter.matches(&a) {
+					page = append(page, a)
+				}
+			}
+			k, id = cursor.Next()
+		}
+		if k == nil {
+			last = nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return page, last, nil
+}
+
+// GetAssessment は指定したIDの診断結果を返します
+func (s *BoltStore) GetAssessment(id string) (*models.Assessment, error) {
+	var a models.Assessment
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(assessmentsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAssessments は条件に一致する診断結果を新しい順に返します
+func (s *BoltStore) ListAssessments(filter AssessmentFilter) ([]models.Assessment, error) {
+	assessments := []models.Assessment{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(assessmentsBucket).ForEach(func(_, data []byte) error {
+			var a models.Assessment
+			if err := json.Unmarshal(data, &a); err != nil {
+				return err
+			}
+			if !filter.matches(&a) {
+				return nil
+			}
+			assessments = append(assessments, a)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(assessments, func(i, j int) bool {
+		return assessments[i].CreatedAt.After(assessments[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(assessments) > filter.Limit {
+		assessments = assessments[:filter.Limit]
+	}
+	return assessments, nil
+}
+
+// CreateSession は新しいセッションを保存します
+func (s *BoltStore) CreateSession(session *models.Session) error {
+	if session.ID == "" {
+		session.ID = NewID()
+	}
+	now := time.Now().UTC()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.UpdatedAt = now
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(session.ID), data)
+	})
+}
+
+// GetSession は指定したIDのセッションを返します
+func (s *BoltStore) GetSession(id string) (*models.Session, error) {
+	var session models.Session
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(sessionsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UpdateSession は指定したIDのセッションを fn で更新して保存します
+func (s *BoltStore) UpdateSession(id string, fn func(session *models.Session) error) (*models.Session, error) {
+	return s.CompleteSession(id, func(session *models.Session) (*models.Assessment, error) {
+		return nil, fn(session)
+	})
+}
+
+// CompleteSession は指定したIDのセッションを fn で更新し、fn が返した診断結果と同じトランザクションで保存します
+func (s *BoltStore) CompleteSession(id string, fn func(session *models.Session) (*models.Assessment, error)) (*models.Session, error) {
+	var session models.Session
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sessionsBucket)
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, &session); err != nil {
+			return err
+		}
+
+		a, err := fn(&session)
+		if err != nil {
+			return err
+		}
+		session.UpdatedAt = time.Now().UTC()
+		if a != nil {
+			if a.ID == "" {
+				a.ID = NewID()
+			}
+			if a.CreatedAt.IsZero() {
+				a.CreatedAt = session.UpdatedAt
+			}
+			if err := putAssessment(tx, a); err != nil {
+				return err
+			}
+			session.AssessmentID = a.ID
+		}
+
+		updated, err := json.Marshal(&session)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(id), updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions は実施のセッションを開始した順に返します
+func (s *BoltStore) ListSessions(campaignID string) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(_, data []byte) error {
+			var session models.Session
+			if err := json.Unmarshal(data, &session); err != nil {
+				return err
+			}
+			if session.CampaignID == campaignID {
+				sessions = append(sessions, session)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// userRecord は保存するユーザーの形式です（パスワードのハッシュを含めます）
+type userRecord struct {
+	models.User
+	PasswordHash string `json:"passwordHash"`
+}
+
+// normalizeEmail は索引に使うメールアドレスの形式を返します
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// CreateUser は新しいユーザーを保存します
+func (s *BoltStore) CreateUser(u *models.User) error {
+	if u.ID == "" {
+		u.ID = NewID()
+	}
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(userRecord{User: *u, PasswordHash: u.PasswordHash})
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		emails := tx.Bucket(userEmailsBucket)
+		email := []byte(normalizeEmail(u.Email))
+		if emails.Get(email) != nil {
+			return ErrConflict
+		}
+		if err := emails.Put(email, []byte(u.ID)); err != nil {
+			return err
+		}
+		return tx.Bucket(usersBucket).Put([]byte(u.ID), data)
+	})
+}
+
+// GetUser は指定したIDのユーザーを返します
+func (s *BoltStore) GetUser(id string) (*models.User, error) {
+	var u *models.User
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		u, err = getUser(tx, []byte(id))
+		return err
+	})
+	return u, err
+}
+
+// GetUserByEmail は指定したメールアドレスのユーザーを返します
+func (s *BoltStore) GetUserByEmail(email string) (*models.User, error) {
+	var u *models.User
+	err := s.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(userEmailsBucket).Get([]byte(normalizeEmail(email)))
+		if id == nil {
+			return ErrNotFound
+		}
+		var err error
+		u, err = getUser(tx, id)
+		return err
+	})
+	return u, err
+}
+
+// getUser はトランザクション内でユーザーを読み込みます
+func getUser(tx *bolt.Tx, id []byte) (*models.User, error) {
+	data := tx.Bucket(usersBucket).Get(id)
+	if data == nil {
+		return nil, ErrNotFound
+	}
+	var record userRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	record.User.PasswordHash = record.PasswordHash
+	return &record.User, nil
+}
+
+// CreateOrganization は新しい組織を保存します
+func (s *BoltStore) CreateOrganization(o *models.Organization) error {
+	if o.ID == "" {
+		o.ID = NewID()
+	}
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(organizationsBucket).Put([]byte(o.ID), data)
+	})
+}
+
+// GetOrganization は指定したIDの組織を返します
+func (s *BoltStore) GetOrganization(id string) (*models.Organization, error) {
+	var o models.Organization
+	if err := s.get(organizationsBucket, id, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListOrganizations はすべての組織を名前順に返します
+func (s *BoltStore) ListOrganizations() ([]models.Organization, error) {
+	organizations := []models.Organization{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(organizationsBucket).ForEach(func(_, data []byte) error {
+			var o models.Organization
+			if err := json.Unmarshal(data, &o); err != nil {
+				return err
+			}
+			organizations = append(organizations, o)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(organizations, func(i, j int) bool {
+		return organizations[i].Name < organizations[j].Name
+	})
+	return organizations, nil
+}
+
+// CreateTeam は新しいチームを保存します
+func (s *BoltStore) CreateTeam(t *models.Team) error {
+	if t.ID == "" {
+		t.ID = NewID()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(organizationsBucket).Get([]byte(t.OrganizationID)) == nil {
+			return ErrNotFound
+		}
+		return tx.Bucket(teamsBucket).Put([]byte(t.ID), data)
+	})
+}
+
+// GetTeam は指定したIDのチームを返します
+func (s *BoltStore) GetTeam(id string) (*models.Team, error) {
+	var t models.Team
+	if err := s.get(teamsBucket, id, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListTeams は組織のチームを名前順に返します
+func (s *BoltStore) ListTeams(organizationID string) ([]models.Team, error) {
+	teams := []models.Team{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(teamsBucket).ForEach(func(_, data []byte) error {
+			var t models.Team
+			if err := json.Unmarshal(data, &t); err != nil {
+				return err
+			}
+			if t.OrganizationID == organizationID {
+				teams = append(teams, t)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+	return teams, nil
+}
+
+// membershipKey は所属のキーです（組織ID/チームID/ユーザーID）
+func membershipKey(organizationID, teamID, userID string) []byte {
+	return []byte(organizationID + "/" + teamID + "/" + userID)
+}
+
+// SaveMembership は所属を追加するか、既存の所属の役割を更新します
+func (s *BoltStore) SaveMembership(m *models.Membership) error {
+	key := membershipKey(m.OrganizationID, m.TeamID, m.UserID)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(membershipsBucket)
+		// 既存の所属の場合は追加した日時と承諾を引き継ぐ
+		if data := bucket.Get(key); data != nil {
+			var current models.Membership
+			if err := json.Unmarshal(data, &current); err != nil {
+				return err
+			}
+			m.CreatedAt = current.CreatedAt
+			if current.AcceptedAt != nil {
+				m.AcceptedAt = current.AcceptedAt
+			}
+		}
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = time.Now().UTC()
+		}
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// AcceptMembership は本人による所属の承諾を記録します。既に承諾している場合は承諾した日時を変えません
+func (s *BoltStore) AcceptMembership(organizationID, teamID, userID string, now time.Time) (*models.Membership, error) {
+	key := membershipKey(organizationID, teamID, userID)
+	var m models.Membership
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(membershipsBucket)
+		data := bucket.Get(key)
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		if m.AcceptedAt != nil {
+			return nil
+		}
+
+		accepted := now.UTC()
+		m.AcceptedAt = &accepted
+		updated, err := json.Marshal(&m)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// DeleteMembership は所属を削除します
+func (s *BoltStore) DeleteMembership(organizationID, teamID, userID string) error {
+	key := membershipKey(organizationID, teamID, userID)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(membershipsBucket)
+		if bucket.Get(key) == nil {
+			return ErrNotFound
+		}
+		return bucket.Delete(key)
+	})
+}
+
+// ListMemberships は条件に一致する所属を追加した順に返します
+func (s *BoltStore) ListMemberships(filter MembershipFilter) ([]models.Membership, error) {
+	memberships := []models.Membership{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(membershipsBucket).ForEach(func(_, data []byte) error {
+			var m models.Membership
+			if err := json.Unmarshal(data, &m); err != nil {
+				return err
+			}
+			if filter.matches(&m) {
+				memberships = append(memberships, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(memberships, func(i, j int) bool {
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+	return memberships, nil
+}
+
+// CreateCampaign は新しい実施を保存します
+func (s *BoltStore) CreateCampaign(c *models.Campaign) error {
+	if c.ID == "" {
+		c.ID = NewID()
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(organizationsBucket).Get([]byte(c.OrganizationID)) == nil {
+			return ErrNotFound
+		}
+		return tx.Bucket(campaignsBucket).Put([]byte(c.ID), data)
+	})
+}
+
+// GetCampaign は指定したIDの実施を返します
+func (s *BoltStore) GetCampaign(id string) (*models.Campaign, error) {
+	var c models.Campaign
+	if err := s.get(campaignsBucket, id, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListCampaigns は組織の実施を新しい順に返します
+func (s *BoltStore) ListCampaigns(organizationID string) ([]models.Campaign, error) {
+	campaigns := []models.Campaign{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(campaignsBucket).ForEach(func(_, data []byte) error {
+			var c models.Campaign
+			if err := json.Unmarshal(data, &c); err != nil {
+				return err
+			}
+			if c.OrganizationID == organizationID {
+				campaigns = append(campaigns, c)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+	})
+	return campaigns, nil
+}
+
+// CreateInvitations は招待をまとめて保存します
+func (s *BoltStore) CreateInvitations(invitations []*models.Invitation) error {
+	now := time.Now().UTC()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		campaigns, bucket := tx.Bucket(campaignsBucket), tx.Bucket(invitationsBucket)
+		for _, inv := range invitations {
+			if campaigns.Get([]byte(inv.CampaignID)) == nil {
+				return ErrNotFound
+			}
+			if inv.Token == "" {
+				inv.Token = NewID()
+			}
+			if inv.CreatedAt.IsZero() {
+				inv.CreatedAt = now
+			}
+			data, err := json.Marshal(inv)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(inv.Token), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetInvitation は指定したトークンの招待を返します
+func (s *BoltStore) GetInvitation(token string) (*models.Invitation, error) {
+	var inv models.Invitation
+	if err := s.get(invitationsBucket, token, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListInvitations は実施の招待を作成した順に返します
+func (s *BoltStore) ListInvitations(campaignID string) ([]models.Invitation, error) {
+	invitations := []models.Invitation{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(invitationsBucket).ForEach(func(_, data []byte) error {
+			var inv models.Invitation
+			if err := json.Unmarshal(data, &inv); err != nil {
+				return err
+			}
+			if inv.CampaignID == campaignID {
+				invitations = append(invitations, inv)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+	return invitations, nil
+}
+
+// RedeemInvitation は招待の使用回数を1つ増やします
+func (s *BoltStore) RedeemInvitation(token string, now time.Time) (*models.Invitation, error) {
+	var inv models.Invitation
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(invitationsBucket)
+		data := bucket.Get([]byte(token))
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, &inv); err != nil {
+			return err
+		}
+		if inv.Expired(now) {
+			return ErrInvitationExpired
+		}
+		if inv.Exhausted() {
+			return ErrInvitationUsed
+		}
+		inv.Uses++
+
+		updated, err := json.Marshal(&inv)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(token), updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// get は bucket から指定したIDのレコードを読み込みます
+func (s *BoltStore) get(bucket []byte, id string, v any) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, v)
+	})
+}
+
+// Close はデータベースを閉じます
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,473 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"hpcs/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTestStore はテスト用の一時データベースを開きます
+func openTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+	s, err := OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestBoltStoreAssessments(t *testing.T) {
+	s := openTestStore(t)
+
+	first := &models.Assessment{
+		InstrumentID:      "hpcs-74",
+		InstrumentVersion: "1.0.0",
+		Responses:         []models.Response{{QuestionID: 1, Score: 5}},
+		Result:            models.Result{Neuroticism: models.Float64(5)},
+		CreatedAt:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := s.SaveAssessment(first); err != nil {
+		t.Fatalf("Failed to save assessment: %v", err)
+	}
+	if first.ID == "" {
+		t.Fatal("Expected ID to be assigned")
+	}
+
+	second := &models.Assessment{InstrumentID: "short-4"}
+	if err := s.SaveAssessment(second); err != nil {
+		t.Fatalf("Failed to save assessment: %v", err)
+	}
+	if second.CreatedAt.IsZero() {
+		t.Error("Expected CreatedAt to be assigned")
+	}
+
+	got, err := s.GetAssessment(first.ID)
+	if err != nil {
+		t.Fatalf("Failed to get assessment: %v", err)
+	}
+	if got.InstrumentVersion != "1.0.0" || *got.Result.Neuroticism != 5 || len(got.Responses) != 1 {
+		t.Errorf("Unexpected assessment: %+v", got)
+	}
+
+	if _, err := s.GetAssessment("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// 新しい順に返される
+	all, err := s.ListAssessments(AssessmentFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list assessments: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != second.ID {
+		t.Errorf("Expected newest assessment first, got %+v", all)
+	}
+
+	filtered, _ := s.ListAssessments(AssessmentFilter{InstrumentID: "hpcs-74"})
+	if len(filtered) != 1 || filtered[0].ID != first.ID {
+		t.Errorf("Expected only hpcs-74 assessments, got %+v", filtered)
+	}
+
+	ranged, _ := s.ListAssessments(AssessmentFilter{
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	if len(ranged) != 1 || ranged[0].ID != first.ID {
+		t.Errorf("Expected only assessments created on 2024-01-01, got %+v", ranged)
+	}
+
+	owned := &models.Assessment{InstrumentID: "hpcs-74", OwnerID: "user-1"}
+	s.SaveAssessment(owned)
+	mine, _ := s.ListAssessments(AssessmentFilter{OwnerID: "user-1"})
+	if len(mine) != 1 || mine[0].ID != owned.ID {
+		t.Errorf("Expected only assessments owned by user-1, got %+v", mine)
+	}
+	// 招待から匿名で回答された診断結果は所有者のいない診断結果に含めない
+	s.SaveAssessment(&models.Assessment{InstrumentID: "hpcs-74", CampaignID: "campaign-1"})
+	unowned, _ := s.ListAssessments(AssessmentFilter{Unowned: true})
+	if len(unowned) != 2 {
+		t.Errorf("Expected 2 anonymous assessments, got %d", len(unowned))
+	}
+	if team, _ := s.ListAssessments(AssessmentFilter{OwnerIDs: []string{"user-1", "user-2"}}); len(team) != 1 {
+		t.Errorf("Expected 1 assessment owned by the listed users, got %d", len(team))
+	}
+	if none, _ := s.ListAssessments(AssessmentFilter{OwnerIDs: []string{}}); len(none) != 0 {
+		t.Errorf("Expected no assessments for an empty owner list, got %d", len(none))
+	}
+
+	limited, _ := s.ListAssessments(AssessmentFilter{Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("Expected 1 assessment, got %d", len(limited))
+	}
+}
+
+func TestBoltStoreEachAssessment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := OpenBolt(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	for _, day := range []int{3, 1, 2} {
+		s.SaveAssessment(&models.Assessment{ID: fmt.Sprintf("day-%d", day), InstrumentID: "hpcs-74", CreatedAt: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)})
+	}
+	s.SaveAssessment(&models.Assessment{ID: "other", InstrumentID: "other"})
+	// 作成日時を変えて上書きしても重複しない
+	s.SaveAssessment(&models.Assessment{ID: "day-3", InstrumentID: "hpcs-74", CreatedAt: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)})
+
+	each := func(s *BoltStore, filter AssessmentFilter) []string {
+		t.Helper()
+		var ids []string
+		if err := s.EachAssessment(filter, func(a *models.Assessment) error {
+			ids = append(ids, a.ID)
+			return nil
+		}); err != nil {
+			t.Fatalf("Failed to iterate assessments: %v", err)
+		}
+		return ids
+	}
+	if got := fmt.Sprint(each(s, AssessmentFilter{InstrumentID: "hpcs-74"})); got != "[day-1 day-2 day-3]" {
+		t.Errorf("Expected assessments in creation order, got %s", got)
+	}
+	if got := fmt.Sprint(each(s, AssessmentFilter{InstrumentID: "hpcs-74", Limit: 1})); got != "[day-1]" {
+		t.Errorf("Expected the oldest assessment, got %s", got)
+	}
+
+	// 複数のページに分けて読み込み、fn の中から書き込んでも待たされない
+	defer func(size int) { assessmentPageSize = size }(assessmentPageSize)
+	assessmentPageSize = 1
+	var paged []string
+	if err := s.EachAssessment(AssessmentFilter{InstrumentID: "hpcs-74"}, func(a *models.Assessment) error {
+		paged = append(paged, a.ID)
+		return s.SaveAssessment(&models.Assessment{InstrumentID: "later"})
+	}); err != nil {
+		t.Fatalf("Failed to iterate assessments: %v", err)
+	}
+	if got := fmt.Sprint(paged); got != "[day-1 day-2 day-3]" {
+		t.Errorf("Expected all pages in creation order, got %s", got)
+	}
+	if got := fmt.Sprint(each(s, AssessmentFilter{InstrumentID: "hpcs-74", Limit: 2})); got != "[day-1 day-2]" {
+		t.Errorf("Expected the limit to apply across pages, got %s", got)
+	}
+
+	// fn のエラーで中断する
+	stop := errors.New("stop")
+	calls := 0
+	if err := s.EachAssessment(AssessmentFilter{}, func(*models.Assessment) error { calls++; return stop }); !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected iteration to stop at the first error, got %v after %d calls", err, calls)
+	}
+
+	// 索引のない既存のデータベースは開くときに索引を作成する
+	s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(assessmentTimesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(assessmentTimesBucket)
+		return err
+	})
+	s.Close()
+	s, err = OpenBolt(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer s.Close()
+	if got := fmt.Sprint(each(s, AssessmentFilter{InstrumentID: "hpcs-74"})); got != "[day-1 day-2 day-3]" {
+		t.Errorf("Expected the index to be rebuilt, got %s", got)
+	}
+}
+
+func TestBoltStoreSessions(t *testing.T) {
+	s := openTestStore(t)
+
+	session := &models.Session{InstrumentID: "hpcs-74", Status: models.SessionInProgress}
+	if err := s.CreateSession(session); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if session.ID == "" || session.CreatedAt.IsZero() {
+		t.Fatalf("Expected ID and CreatedAt to be assigned, got %+v", session)
+	}
+
+	updated, err := s.UpdateSession(session.ID, func(s *models.Session) error {
+		s.Upsert([]models.Response{{QuestionID: 1, Score: 2}, {QuestionID: 2, Score: 3}})
+		s.Upsert([]models.Response{{QuestionID: 1, Score: 4}})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to update session: %v", err)
+	}
+	if len(updated.Responses) != 2 || updated.Responses[0].Score != 4 {
+		t.Errorf("Expected upserted responses, got %+v", updated.Responses)
+	}
+
+	// fn がエラーを返した場合は保存されない
+	sentinel := errors.New("rejected")
+	_, err = s.UpdateSession(session.ID, func(s *models.Session) error {
+		s.Responses = nil
+		return sentinel
+	})
+	if !errors.Is(err, sentinel) {
+		t.Errorf("Expected sentinel error, got %v", err)
+	}
+
+	got, err := s.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if len(got.Responses) != 2 {
+		t.Errorf("Expected rejected update to be rolled back, got %+v", got.Responses)
+	}
+
+	if _, err := s.GetSession("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.UpdateSession("missing", func(*models.Session) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestBoltStoreCompleteSession(t *testing.T) {
+	s := openTestStore(t)
+
+	session := &models.Session{InstrumentID: "hpcs-74", Status: models.SessionInProgress}
+	if err := s.CreateSession(session); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	// fn が返した診断結果はセッションと同じトランザクションで保存される
+	completed, err := s.CompleteSession(session.ID, func(s *models.Session) (*models.Assessment, error) {
+		s.Status = models.SessionCompleted
+		return &models.Assessment{InstrumentID: s.InstrumentID}, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to complete session: %v", err)
+	}
+	if completed.Status != models.SessionCompleted || completed.AssessmentID == "" {
+		t.Fatalf("Unexpected completed session: %+v", completed)
+	}
+	if a, err := s.GetAssessment(completed.AssessmentID); err != nil || a.CreatedAt.IsZero() {
+		t.Errorf("Expected the assessment to be saved, got %+v, %v", a, err)
+	}
+
+	// fn がエラーを返した場合は診断結果もセッションも保存されない
+	sentinel := errors.New("rejected")
+	_, err = s.CompleteSession(session.ID, func(s *models.Session) (*models.Assessment, error) {
+		s.AssessmentID = ""
+		return &models.Assessment{ID: "rejected"}, sentinel
+	})
+	if !errors.Is(err, sentinel) {
+		t.Errorf("Expected sentinel error, got %v", err)
+	}
+	if _, err := s.GetAssessment("rejected"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected rejected assessment not to be saved, got %v", err)
+	}
+	if got, err := s.GetSession(session.ID); err != nil || got.AssessmentID != completed.AssessmentID {
+		t.Errorf("Expected rejected update to be rolled back, got %+v, %v", got, err)
+	}
+}
+
+func TestBoltStoreUsers(t *testing.T) {
+	s := openTestStore(t)
+
+	user := &models.User{Email: "Sato@Example.com", Name: "佐藤", PasswordHash: "hash"}
+	if err := s.CreateUser(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if user.ID == "" || user.CreatedAt.IsZero() {
+		t.Fatalf("Expected ID and CreatedAt to be assigned, got %+v", user)
+	}
+
+	// パスワードのハッシュも保存される
+	got, err := s.GetUser(user.ID)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if got.Email != "Sato@Example.com" || got.PasswordHash != "hash" {
+		t.Errorf("Unexpected user: %+v", got)
+	}
+
+	// メールアドレスは大文字と小文字を区別しない
+	byEmail, err := s.GetUserByEmail(" sato@example.COM ")
+	if err != nil || byEmail.ID != user.ID {
+		t.Errorf("Expected to find user by email, got %+v, %v", byEmail, err)
+	}
+	if err := s.CreateUser(&models.User{Email: "sato@example.com"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for duplicate email, got %v", err)
+	}
+
+	if _, err := s.GetUser("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.GetUserByEmail("missing@example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestBoltStoreOrganizations(t *testing.T) {
+	s := openTestStore(t)
+
+	org := &models.Organization{Name: "営業本部"}
+	if err := s.CreateOrganization(org); err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := s.CreateOrganization(&models.Organization{Name: "開発本部"}); err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	organizations, err := s.ListOrganizations()
+	if err != nil || len(organizations) != 2 {
+		t.Fatalf("Expected 2 organizations, got %+v, %v", organizations, err)
+	}
+
+	// 存在しない組織のチームは作成できない
+	if err := s.CreateTeam(&models.Team{OrganizationID: "missing", Name: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	team := &models.Team{OrganizationID: org.ID, Name: "第一営業部"}
+	if err := s.CreateTeam(team); err != nil {
+		t.Fatalf("Failed to create team: %v", err)
+	}
+	if got, err := s.GetTeam(team.ID); err != nil || got.OrganizationID != org.ID {
+		t.Errorf("Unexpected team: %+v, %v", got, err)
+	}
+	if teams, err := s.ListTeams(org.ID); err != nil || len(teams) != 1 {
+		t.Errorf("Expected 1 team, got %+v, %v", teams, err)
+	}
+
+	// 所属の追加と役割の更新
+	memberships := []*models.Membership{
+		{OrganizationID: org.ID, UserID: "admin", Role: models.RoleOrgAdmin},
+		{OrganizationID: org.ID, TeamID: team.ID, UserID: "manager", Role: models.RoleParticipant},
+		{OrganizationID: org.ID, TeamID: team.ID, UserID: "member", Role: models.RoleParticipant},
+	}
+	for _, m := range memberships {
+		if err := s.SaveMembership(m); err != nil {
+			t.Fatalf("Failed to save membership: %v", err)
+		}
+	}
+	created := memberships[1].CreatedAt
+	updated := &models.Membership{OrganizationID: org.ID, TeamID: team.ID, UserID: "manager", Role: models.RoleManager}
+	if err := s.SaveMembership(updated); err != nil {
+		t.Fatalf("Failed to update membership: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created) {
+		t.Errorf("Expected CreatedAt to be kept, got %v", updated.CreatedAt)
+	}
+
+	// 承諾した所属は役割を更新しても承諾を引き継ぐ
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	if accepted, err := s.AcceptMembership(org.ID, "", "admin", now); err != nil || accepted.AcceptedAt == nil || !accepted.AcceptedAt.Equal(now) {
+		t.Fatalf("Unexpected accepted membership: %+v, %v", accepted, err)
+	}
+	if accepted, err := s.AcceptMembership(org.ID, "", "admin", now.Add(time.Hour)); err != nil || !accepted.AcceptedAt.Equal(now) {
+		t.Errorf("Expected AcceptedAt to be kept, got %+v, %v", accepted, err)
+	}
+	if _, err := s.AcceptMembership(org.ID, "", "nobody", now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := s.SaveMembership(&models.Membership{OrganizationID: org.ID, UserID: "admin", Role: models.RoleParticipant}); err != nil {
+		t.Fatalf("Failed to update membership: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   MembershipFilter
+		expected int
+	}{
+		{name: "組織のすべての所属", filter: MembershipFilter{OrganizationID: org.ID}, expected: 3},
+		{name: "チームの所属", filter: MembershipFilter{TeamID: team.ID}, expected: 2},
+		{name: "ユーザーの所属", filter: MembershipFilter{UserID: "manager"}, expected: 1},
+		{name: "該当なし", filter: MembershipFilter{UserID: "nobody"}, expected: 0},
+		{name: "承諾済みの所属", filter: MembershipFilter{OrganizationID: org.ID, Accepted: true}, expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ListMemberships(tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to list memberships: %v", err)
+			}
+			if len(got) != tt.expected {
+				t.Errorf("Expected %d memberships, got %d", tt.expected, len(got))
+			}
+		})
+	}
+	if got, _ := s.ListMemberships(MembershipFilter{UserID: "manager"}); got[0].Role != models.RoleManager {
+		t.Errorf("Expected role to be updated, got %s", got[0].Role)
+	}
+
+	if err := s.DeleteMembership(org.ID, team.ID, "member"); err != nil {
+		t.Fatalf("Failed to delete membership: %v", err)
+	}
+	if err := s.DeleteMembership(org.ID, team.ID, "member"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestBoltStoreCampaigns(t *testing.T) {
+	s := openTestStore(t)
+
+	if err := s.CreateCampaign(&models.Campaign{OrganizationID: "missing", Name: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown organization, got %v", err)
+	}
+	org := &models.Organization{Name: "Acme"}
+	s.CreateOrganization(org)
+	campaign := &models.Campaign{OrganizationID: org.ID, Name: "2024年度新入社員", InstrumentID: "hpcs-74"}
+	if err := s.CreateCampaign(campaign); err != nil {
+		t.Fatalf("Failed to create campaign: %v", err)
+	}
+	if campaigns, err := s.ListCampaigns(org.ID); err != nil || len(campaigns) != 1 || campaigns[0].ID != campaign.ID {
+		t.Errorf("Unexpected campaigns: %+v, %v", campaigns, err)
+	}
+
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	single := &models.Invitation{CampaignID: campaign.ID, MaxUses: 1}
+	multi := &models.Invitation{CampaignID: campaign.ID}
+	old := &models.Invitation{CampaignID: campaign.ID, ExpiresAt: &expired}
+	if err := s.CreateInvitations([]*models.Invitation{single, multi, old}); err != nil {
+		t.Fatalf("Failed to create invitations: %v", err)
+	}
+	if single.Token == "" || single.Token == multi.Token {
+		t.Fatalf("Expected unique tokens, got %q and %q", single.Token, multi.Token)
+	}
+	if err := s.CreateInvitations([]*models.Invitation{{CampaignID: "missing"}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown campaign, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{name: "1回限りの招待", token: single.Token},
+		{name: "使用済みの招待", token: single.Token, expected: ErrInvitationUsed},
+		{name: "回数無制限の招待", token: multi.Token},
+		{name: "回数無制限の招待（2回目）", token: multi.Token},
+		{name: "期限切れの招待", token: old.Token, expected: ErrInvitationExpired},
+		{name: "存在しない招待", token: "missing", expected: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RedeemInvitation(tt.token, now)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	invitations, err := s.ListInvitations(campaign.ID)
+	if err != nil || len(invitations) != 3 {
+		t.Fatalf("Expected 3 invitations, got %+v, %v", invitations, err)
+	}
+	if got, _ := s.GetInvitation(multi.Token); got.Uses != 2 {
+		t.Errorf("Expected 2 uses, got %d", got.Uses)
+	}
+
+	s.CreateSession(&models.Session{CampaignID: campaign.ID, InvitationToken: single.Token})
+	s.CreateSession(&models.Session{})
+	if sessions, err := s.ListSessions(campaign.ID); err != nil || len(sessions) != 1 {
+		t.Errorf("Expected 1 campaign session, got %+v, %v", sessions, err)
+	}
+}
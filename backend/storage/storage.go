@@ -0,0 +1,171 @@
+// Package storage は診断結果の永続化を行います
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"hpcs/models"
+	"slices"
+	"time"
+)
+
+// ErrNotFound は指定したレコードが存在しないことを表します
+var ErrNotFound = errors.New("not found")
+
+// ErrInvitationExpired と ErrInvitationUsed は招待を使えないことを表します
+var (
+	ErrInvitationExpired = errors.New("invitation has expired")
+	ErrInvitationUsed    = errors.New("invitation has already been used")
+)
+
+// ErrConflict は一意であるべき値（メールアドレスなど）が既に使われていることを表します
+var ErrConflict = errors.New("already exists")
+
+// AssessmentFilter は保存済みの診断結果を絞り込む条件です
+type AssessmentFilter struct {
+	InstrumentID string
+	// CampaignID は招待から回答した実施のIDです（空の場合は制限なし）
+	CampaignID string
+	// From と To は作成日時の範囲です（From 以降、To より前。ゼロ値の場合は制限なし）
+	From time.Time
+	To   time.Time
+	// OwnerID は所有者のユーザーIDです（空の場合は制限なし）
+	OwnerID string
+	// Unowned は所有者のいない（匿名で採点した）診断結果のみに絞り込みます。招待から回答された診断結果は含みません
+	Unowned bool
+	// OwnerIDs は所有者のユーザーIDの一覧です。nil でない場合はいずれかのユーザーの診断結果のみに絞り込みます（空の場合は一致しません）
+	OwnerIDs []string
+	// Limit は返す件数の上限です（0 の場合は無制限）
+	Limit int
+}
+
+// matches は診断結果が条件に一致するかどうかを返します
+func (f AssessmentFilter) matches(a *models.Assessment) bool {
+	if f.InstrumentID != "" && a.InstrumentID != f.InstrumentID {
+		return false
+	}
+	if f.CampaignID != "" && a.CampaignID != f.CampaignID {
+		return false
+	}
+	if f.OwnerID != "" && a.OwnerID != f.OwnerID {
+		return false
+	}
+	if f.Unowned && (a.OwnerID != "" || a.CampaignID != "") {
+		return false
+	}
+	if f.OwnerIDs != nil && !slices.Contains(f.OwnerIDs, a.OwnerID) {
+		return false
+	}
+	if !f.From.IsZero() && a.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !a.CreatedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// MembershipFilter は所属を絞り込む条件です。空の項目は制限しません
+type MembershipFilter struct {
+	OrganizationID string
+	TeamID         string
+	UserID         string
+	// Accepted は本人が承諾した所属のみに絞り込みます
+	Accepted bool
+}
+
+// matches は所属が条件に一致するかどうかを返します
+func (f MembershipFilter) matches(m *models.Membership) bool {
+	return (f.OrganizationID == "" || m.OrganizationID == f.OrganizationID) &&
+		(f.TeamID == "" || m.TeamID == f.TeamID) &&
+		(f.UserID == "" || m.UserID == f.UserID) &&
+		(!f.Accepted || m.AcceptedAt != nil)
+}
+
+// Store は診断結果の保存先です
+type Store interface {
+	// SaveAssessment は診断結果を保存します。ID と作成日時が未設定の場合は割り当てます
+	SaveAssessment(a *models.Assessment) error
+	// SaveAssessments は複数の診断結果をまとめて保存します。すべて保存されるか、いずれも保存されないかのどちらかです
+	SaveAssessments(assessments []*models.Assessment) error
+	// GetAssessment は指定したIDの診断結果を返します
+	GetAssessment(id string) (*models.Assessment, error)
+	// ListAssessments は条件に一致する診断結果を新しい順に返します
+	ListAssessments(filter AssessmentFilter) ([]models.Assessment, error)
+	// EachAssessment は条件に一致する診断結果を古い順に1件ずつ fn に渡します。fn がエラーを返した場合は中断します。
+	// fn の実行中は読み取りのトランザクションを開いたままにしません
+	EachAssessment(filter AssessmentFilter, fn func(a *models.Assessment) error) error
+
+	// CreateSession は新しいセッションを保存します。ID と作成日時が未設定の場合は割り当てます
+	CreateSession(s *models.Session) error
+	// GetSession は指定したIDのセッションを返します
+	GetSession(id string) (*models.Session, error)
+	// UpdateSession は指定したIDのセッションを fn で更新して保存します。
+	// 読み込みから保存までは1つのトランザクションで行われます
+	UpdateSession(id string, fn func(s *models.Session) error) (*models.Session, error)
+	// CompleteSession は UpdateSession と同様にセッションを更新します。fn が診断結果を返した場合は
+	// 同じトランザクションで保存し、セッションの AssessmentID に設定します
+	CompleteSession(id string, fn func(s *models.Session) (*models.Assessment, error)) (*models.Session, error)
+	// ListSessions は実施のセッションを開始した順に返します
+	ListSessions(campaignID string) ([]models.Session, error)
+
+	// CreateUser は新しいユーザーを保存します。ID と作成日時が未設定の場合は割り当てます。
+	// メールアドレスが既に登録されている場合は ErrConflict を返します
+	CreateUser(u *models.User) error
+	// GetUser は指定したIDのユーザーを返します
+	GetUser(id string) (*models.User, error)
+	// GetUserByEmail は指定したメールアドレスのユーザーを返します（大文字と小文字は区別しません）
+	GetUserByEmail(email string) (*models.User, error)
+
+	// CreateOrganization は新しい組織を保存します。ID と作成日時が未設定の場合は割り当てます
+	CreateOrganization(o *models.Organization) error
+	// GetOrganization は指定したIDの組織を返します
+	GetOrganization(id string) (*models.Organization, error)
+	// ListOrganizations はすべての組織を名前順に返します
+	ListOrganizations() ([]models.Organization, error)
+	// CreateTeam は新しいチームを保存します。組織が存在しない場合は ErrNotFound を返します
+	CreateTeam(t *models.Team) error
+	// GetTeam は指定したIDのチームを返します
+	GetTeam(id string) (*models.Team, error)
+	// ListTeams は組織のチームを名前順に返します
+	ListTeams(organizationID string) ([]models.Team, error)
+
+	// SaveMembership は所属を追加するか、既存の所属の役割を更新します。既存の所属の承諾は引き継ぎます
+	SaveMembership(m *models.Membership) error
+	// AcceptMembership は本人による所属の承諾を記録します。teamID が空の場合は組織への所属を承諾します。
+	// 所属が存在しない場合は ErrNotFound を返します
+	AcceptMembership(organizationID, teamID, userID string, now time.Time) (*models.Membership, error)
+	// DeleteMembership は所属を削除します。teamID が空の場合は組織への所属を削除します
+	DeleteMembership(organizationID, teamID, userID string) error
+	// ListMemberships は条件に一致する所属を返します
+	ListMemberships(filter MembershipFilter) ([]models.Membership, error)
+
+	// CreateCampaign は新しい実施を保存します。組織が存在しない場合は ErrNotFound を返します
+	CreateCampaign(c *models.Campaign) error
+	// GetCampaign は指定したIDの実施を返します
+	GetCampaign(id string) (*models.Campaign, error)
+	// ListCampaigns は組織の実施を新しい順に返します
+	ListCampaigns(organizationID string) ([]models.Campaign, error)
+	// CreateInvitations は招待をまとめて保存します。トークンと作成日時が未設定の場合は割り当てます。
+	// 実施が存在しない場合は ErrNotFound を返します
+	CreateInvitations(invitations []*models.Invitation) error
+	// GetInvitation は指定したトークンの招待を返します
+	GetInvitation(token string) (*models.Invitation, error)
+	// ListInvitations は実施の招待を作成した順に返します
+	ListInvitations(campaignID string) ([]models.Invitation, error)
+	// RedeemInvitation は招待の使用回数を1つ増やします。
+	// 有効期限が切れている場合は ErrInvitationExpired を、使用回数を使い切っている場合は ErrInvitationUsed を返します
+	RedeemInvitation(token string, now time.Time) (*models.Invitation, error)
+
+	Close() error
+}
+
+// NewID はランダムなレコードIDを生成します
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("failed to generate id: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
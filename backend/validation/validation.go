@@ -0,0 +1,118 @@
+// Package validation は回答データを検査し、見つかったすべての問題を項目ごとのエラーとして返します
+package validation
+
+import (
+	"fmt"
+	"hpcs/i18n"
+	"hpcs/models"
+	"strings"
+)
+
+// エラーコード
+const (
+	CodeOutOfRange      = "out_of_range"
+	CodeUnknownQuestion = "unknown_question"
+	CodeDuplicate       = "duplicate"
+	CodeConflicting     = "conflicting"
+	CodeInvalidValue    = "invalid_value"
+)
+
+// FieldError はリクエスト内の1つの項目に関する問題です
+type FieldError struct {
+	// Field は問題のある項目の位置です（例: responses[3].score）
+	Field      string `json:"field"`
+	QuestionID int    `json:"questionId"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+
+	message *i18n.Message
+}
+
+// newFieldError は翻訳可能なメッセージを持つ FieldError を作成します
+func newFieldError(field string, questionID int, code string, message *i18n.Message) FieldError {
+	return FieldError{
+		Field:      field,
+		QuestionID: questionID,
+		Code:       code,
+		Message:    message.Error(),
+		message:    message,
+	}
+}
+
+// InvalidValue は回答の値が整数として読み取れない場合の FieldError を作成します
+func InvalidValue(field string, questionID int, value string) FieldError {
+	return newFieldError(field, questionID, CodeInvalidValue, i18n.New("validation.invalid_value", questionID, value))
+}
+
+// Errors は検出された問題の一覧です
+type Errors []FieldError
+
+// Error はすべての問題のメッセージを連結して返します
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Localize はメッセージを指定した言語に翻訳した一覧を返します
+func (e Errors) Localize(lang string) Errors {
+	localized := make(Errors, len(e))
+	for i, fe := range e {
+		if fe.message != nil {
+			fe.Message = fe.message.In(lang)
+		}
+		localized[i] = fe
+	}
+	return localized
+}
+
+// ForRespondent は複数の回答者を含むリクエストの i 番目の回答者の問題として位置とメッセージを付け替えます
+func (e Errors) ForRespondent(i int) Errors {
+	prefixed := make(Errors, len(e))
+	for j, fe := range e {
+		prefixed[j] = newFieldError(fmt.Sprintf("respondents[%d].%s", i, fe.Field), fe.QuestionID, fe.Code,
+			i18n.New("validation.respondent", i, fe.message))
+	}
+	return prefixed
+}
+
+// Responses は回答データを検査します。問題がない場合は nil を返します
+func Responses(inst *models.Instrument, responses []models.Response) Errors {
+	var errs Errors
+	first := make(map[int]int, len(responses))
+
+	for i, response := range responses {
+		id := response.QuestionID
+
+		// スコアの範囲チェック
+		if !inst.InRange(response.Score) {
+			errs = append(errs, newFieldError(fmt.Sprintf("responses[%d].score", i), id, CodeOutOfRange,
+				i18n.New("validation.out_of_range", id, inst.Scale.Min, inst.Scale.Max)))
+		}
+
+		// 質問IDの有効性チェック
+		if _, exists := inst.Bank().Question(id); !exists {
+			errs = append(errs, newFieldError(fmt.Sprintf("responses[%d].questionId", i), id, CodeUnknownQuestion,
+				i18n.New("validation.unknown_question", id)))
+		}
+
+		// 同じ質問への重複した回答のチェック
+		j, seen := first[id]
+		if !seen {
+			first[id] = i
+			continue
+		}
+		field := fmt.Sprintf("responses[%d].questionId", i)
+		if responses[j].Score != response.Score {
+			errs = append(errs, newFieldError(field, id, CodeConflicting,
+				i18n.New("validation.conflicting", id, j, responses[j].Score, i, response.Score)))
+		} else {
+			errs = append(errs, newFieldError(field, id, CodeDuplicate,
+				i18n.New("validation.duplicate", id, j)))
+		}
+	}
+
+	return errs
+}
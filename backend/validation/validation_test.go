@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"hpcs/models"
+	"strings"
+	"testing"
+)
+
+func testInstrument(t *testing.T) *models.Instrument {
+	t.Helper()
+	inst, err := models.ParseInstrument([]byte(`
+id: test
+scale: {min: 1, max: 5}
+dimensions: [{id: neuroticism}]
+items:
+  - {id: 1, category: neuroticism}
+  - {id: 2, category: neuroticism}
+  - {id: 3, category: neuroticism}
+`), "yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse instrument: %v", err)
+	}
+	return inst
+}
+
+func TestResponses(t *testing.T) {
+	inst := testInstrument(t)
+
+	tests := []struct {
+		name      string
+		responses []models.Response
+		want      []FieldError
+	}{
+		{
+			name:      "問題なし",
+			responses: []models.Response{{QuestionID: 1, Score: 1}, {QuestionID: 2, Score: 5}},
+		},
+		{
+			name:      "スコアが範囲外",
+			responses: []models.Response{{QuestionID: 1, Score: 6}},
+			want:      []FieldError{{Field: "responses[0].score", QuestionID: 1, Code: CodeOutOfRange}},
+		},
+		{
+			name:      "存在しない質問ID",
+			responses: []models.Response{{QuestionID: 1, Score: 3}, {QuestionID: 99, Score: 3}},
+			want:      []FieldError{{Field: "responses[1].questionId", QuestionID: 99, Code: CodeUnknownQuestion}},
+		},
+		{
+			name:      "同じ回答の重複",
+			responses: []models.Response{{QuestionID: 2, Score: 4}, {QuestionID: 2, Score: 4}},
+			want:      []FieldError{{Field: "responses[1].questionId", QuestionID: 2, Code: CodeDuplicate}},
+		},
+		{
+			name:      "矛盾する回答",
+			responses: []models.Response{{QuestionID: 2, Score: 4}, {QuestionID: 3, Score: 1}, {QuestionID: 2, Score: 1}},
+			want:      []FieldError{{Field: "responses[2].questionId", QuestionID: 2, Code: CodeConflicting}},
+		},
+		{
+			name: "複数の問題をまとめて返す",
+			responses: []models.Response{
+				{QuestionID: 1, Score: 0},
+				{QuestionID: 42, Score: 9},
+				{QuestionID: 1, Score: 3},
+			},
+			want: []FieldError{
+				{Field: "responses[0].score", QuestionID: 1, Code: CodeOutOfRange},
+				{Field: "responses[1].score", QuestionID: 42, Code: CodeOutOfRange},
+				{Field: "responses[1].questionId", QuestionID: 42, Code: CodeUnknownQuestion},
+				{Field: "responses[2].questionId", QuestionID: 1, Code: CodeConflicting},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Responses(inst, tt.responses)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Expected %d errors, got %d: %v", len(tt.want), len(errs), errs)
+			}
+			for i, want := range tt.want {
+				got := errs[i]
+				if got.Field != want.Field || got.QuestionID != want.QuestionID || got.Code != want.Code {
+					t.Errorf("Error %d: expected %+v, got %+v", i, want, got)
+				}
+				if got.Message == "" {
+					t.Errorf("Error %d: expected a message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorsError(t *testing.T) {
+	errs := Responses(testInstrument(t), []models.Response{{QuestionID: 1, Score: 6}, {QuestionID: 999, Score: 3}})
+
+	message := errs.Error()
+	for _, want := range []string{"invalid score for question 1", "invalid question ID: 999"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected message containing '%s', got '%s'", want, message)
+		}
+	}
+
+	prefixed := errs.ForRespondent(2)
+	if prefixed[0].Field != "respondents[2].responses[0].score" {
+		t.Errorf("Unexpected prefixed field: %s", prefixed[0].Field)
+	}
+	if !strings.HasPrefix(prefixed[0].Message, "respondent 2: invalid score for question 1") {
+		t.Errorf("Unexpected prefixed message: %s", prefixed[0].Message)
+	}
+	if errs[0].Field != "responses[0].score" {
+		t.Errorf("ForRespondent should not modify the original errors, got %s", errs[0].Field)
+	}
+
+	// 翻訳しても位置とコードは変わらない
+	localized := prefixed.Localize("ja")
+	if !strings.HasPrefix(localized[1].Message, "回答者 2: 存在しない質問IDです: 999") {
+		t.Errorf("Unexpected localized message: %s", localized[1].Message)
+	}
+	if localized[1].Field != prefixed[1].Field || localized[1].Code != CodeUnknownQuestion {
+		t.Errorf("Localize should keep field and code, got %+v", localized[1])
+	}
+}